@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/release/tools/gotest2junit/pkg/api"
+)
+
+var (
+	// assertionPattern matches the lines that t.Error, t.Fatal and friends write, which start with the location
+	// of the call, like `    foo_test.go:12: expected 1, got 2`
+	assertionPattern = regexp.MustCompile(`^\s+([^\s:]+\.go):([0-9]+): ?(.*)$`)
+
+	// testifyErrorPattern matches the line of a testify failure block holding the failure, like
+	// `        	Error:      	Not equal: `
+	testifyErrorPattern = regexp.MustCompile(`^\s*\tError:\s*\t(.*)$`)
+
+	// testifyContinuationPattern matches the lines continuing the previous field of a testify failure block
+	testifyContinuationPattern = regexp.MustCompile(`^\s*\t\s*\t(.*)$`)
+
+	// panicPattern matches the line with which the Go runtime reports a panic
+	panicPattern = regexp.MustCompile(`^panic: (.*)$`)
+
+	// stackFramePattern matches the location of a frame in the stack trace of a panic in a test file, like
+	// `	/go/src/github.com/org/repo/foo_test.go:23 +0x28`
+	stackFramePattern = regexp.MustCompile(`^\s+(\S+_test\.go):([0-9]+)(?: \+0x[0-9a-f]+)?$`)
+
+	// benchmarkResultPattern matches the result that a benchmark reports once it completes, like
+	// `BenchmarkFoo-8   	 1000	      1234 ns/op`
+	benchmarkResultPattern = regexp.MustCompile(`(?m)^Benchmark\S*\s+[0-9]+\s+[0-9.]+ ns/op`)
+
+	// testResultPattern matches the line reporting the result of a test, like `--- FAIL: TestFoo (0.00s)`
+	testResultPattern = regexp.MustCompile(`^\s*--- (?:PASS|FAIL|SKIP): (\S+)`)
+
+	// packageFramePattern matches the lines that go test writes for every package, like `ok  	pkg	0.01s`
+	packageFramePattern = regexp.MustCompile(`^(PASS|FAIL|ok  \t.*|FAIL\t.*|\?   \t.*|exit status [0-9]+|coverage: .*|testing: warning: no tests to run|goos: .*|goarch: .*|pkg: .*|cpu: .*)$`)
+)
+
+const (
+	// incompleteTestMessage is the failure message of tests that started but did not complete
+	incompleteTestMessage = "Test did not complete"
+
+	// packageTestName is the name of the test case holding the output of a package that was not written by its tests
+	packageTestName = "package output"
+
+	// packageFailureMessage is the failure message of packages that failed although none of their tests failed
+	packageFailureMessage = "Package failed outside of its tests"
+
+	// subtestsFailedMessage is the failure message of tests that failed because some of their subtests failed,
+	// which is followed by the names of those subtests
+	subtestsFailedMessage = "Subtests failed"
+)
+
+// failure describes why a test failed
+type failure struct {
+	// message is a short description of the failure
+	message string
+
+	// file and line locate the failure in the source, as reported by go test, if known
+	file string
+	line int
+}
+
+// parseFailure finds the failure in the output of a test. Recent versions of go test report which lines of the
+// output are errors, like those written by t.Error, in which case the first failed assertion or testify failure
+// block among them is used. Otherwise a panic is used, or else the last failed assertion or testify failure block
+// before the test was reported as failed, as lines logged by t.Log look like failed assertions and are usually
+// written before them. If none is found, the first line of the output that is not written by go test around every
+// test is used as the message.
+func parseFailure(output, errorOutput string) failure {
+	errorLines := strings.Split(errorOutput, "\n")
+	for i, line := range errorLines {
+		if m := assertionPattern.FindStringSubmatch(line); m != nil {
+			return assertionFailure(m, errorLines[i+1:])
+		}
+	}
+
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if panicPattern.MatchString(line) {
+			f := failure{message: line}
+			for _, frame := range lines[i+1:] {
+				if m := stackFramePattern.FindStringSubmatch(frame); m != nil {
+					f.file, f.line = m[1], atoi(m[2])
+					break
+				}
+			}
+			return f
+		}
+	}
+
+	var fallback string
+	last := -1
+	for i, line := range lines {
+		// older versions of go test write the output of a failed test after its result
+		if last >= 0 && strings.HasPrefix(strings.TrimSpace(line), "--- FAIL") {
+			break
+		}
+		if assertionPattern.MatchString(line) {
+			last = i
+			continue
+		}
+		if len(fallback) == 0 && !isFrameLine(line) {
+			fallback = strings.TrimSpace(line)
+		}
+	}
+	if last >= 0 {
+		return assertionFailure(assertionPattern.FindStringSubmatch(lines[last]), lines[last+1:])
+	}
+	return failure{message: fallback}
+}
+
+// assertionFailure describes the failed assertion matched by assertionPattern, which is followed by the lines
+func assertionFailure(m []string, lines []string) failure {
+	f := failure{message: strings.TrimSpace(m[3])}
+	f.file, f.line = m[1], atoi(m[2])
+	// testify writes its failure block after an assertion line without a message
+	if len(f.message) == 0 {
+		f.message = testifyMessage(lines)
+	}
+	return f
+}
+
+// panicMessage returns the line with which the Go runtime reported a panic in the output, like
+// `panic: test timed out after 10m0s`, if there is one
+func panicMessage(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if panicPattern.MatchString(line) {
+			return line
+		}
+	}
+	return ""
+}
+
+// subtestsFailureMessage describes the failure of a test that failed without a failure of its own, as some of its
+// subtests failed
+func subtestsFailureMessage(name string, tests []*api.TestCase) string {
+	var failed []string
+	for _, test := range tests {
+		// only the direct subtests are named, as their own failures describe those of their subtests
+		subtest := strings.TrimPrefix(test.Name, name+"/")
+		if subtest == test.Name || strings.Contains(subtest, "/") || test.FailureOutput == nil {
+			continue
+		}
+		failed = append(failed, test.Name)
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s", subtestsFailedMessage, strings.Join(failed, ", "))
+}
+
+// testifyMessage returns the error of the testify failure block at the start of the lines, including the lines
+// continuing it, like the expected and actual values of a failed comparison
+func testifyMessage(lines []string) string {
+	var message []string
+	for _, line := range lines {
+		if len(message) == 0 {
+			m := testifyErrorPattern.FindStringSubmatch(line)
+			if m == nil {
+				if testifyContinuationPattern.MatchString(line) || strings.Contains(line, "\tError Trace:") {
+					// fields before the error, like the trace
+					continue
+				}
+				return ""
+			}
+			message = append(message, strings.TrimSpace(m[1]))
+			continue
+		}
+		m := testifyContinuationPattern.FindStringSubmatch(line)
+		if m == nil {
+			break
+		}
+		message = append(message, strings.TrimSpace(m[1]))
+	}
+	return strings.TrimSpace(strings.Join(message, "\n"))
+}
+
+// isFrameLine determines if the line is written by go test around every test, rather than by the test itself
+func isFrameLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) == 0 {
+		return true
+	}
+	for _, prefix := range []string{"=== RUN", "=== PAUSE", "=== CONT", "=== NAME", "--- FAIL", "--- PASS", "--- SKIP"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// atoi converts a number matched by one of the patterns above
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
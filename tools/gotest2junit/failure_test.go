@@ -0,0 +1,242 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/release/tools/gotest2junit/pkg/api"
+)
+
+func TestParseFailure(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		output      string
+		errorOutput string
+		expected    failure
+	}{
+		{
+			name: "error preferred over logs",
+			output: `=== RUN   TestA
+    a_test.go:9: starting
+    a_test.go:10: expected 1, got 2
+    a_test.go:11: cleaning up
+--- FAIL: TestA (0.00s)
+`,
+			errorOutput: "    a_test.go:10: expected 1, got 2\n",
+			expected:    failure{message: "expected 1, got 2", file: "a_test.go", line: 10},
+		},
+		{
+			name: "first of several errors",
+			output: `=== RUN   TestA
+    a_test.go:10: expected 1, got 2
+    a_test.go:11: expected 3, got 4
+--- FAIL: TestA (0.00s)
+`,
+			errorOutput: "    a_test.go:10: expected 1, got 2\n    a_test.go:11: expected 3, got 4\n",
+			expected:    failure{message: "expected 1, got 2", file: "a_test.go", line: 10},
+		},
+		{
+			name: "last assertion without output types",
+			output: `=== RUN   TestA
+    a_test.go:9: starting
+    a_test.go:10: expected 1, got 2
+--- FAIL: TestA (0.00s)
+`,
+			expected: failure{message: "expected 1, got 2", file: "a_test.go", line: 10},
+		},
+		{
+			name: "assertions of subtests after the result are ignored",
+			output: `=== RUN   TestA
+    a_test.go:10: expected 1, got 2
+--- FAIL: TestA (0.00s)
+    --- FAIL: TestA/b (0.00s)
+        a_test.go:20: expected 3, got 4
+`,
+			expected: failure{message: "expected 1, got 2", file: "a_test.go", line: 10},
+		},
+		{
+			name: "assertion after the result",
+			output: `--- FAIL: TestA (0.00s)
+	a_test.go:10: expected 1, got 2
+`,
+			expected: failure{message: "expected 1, got 2", file: "a_test.go", line: 10},
+		},
+		{
+			name: "testify failure block in errors",
+			output: `=== RUN   TestA
+    a_test.go:15:
+        	Error Trace:	a_test.go:15
+        	Error:      	Not equal:
+        	            	expected: 1
+        	            	actual  : 2
+        	Test:       	TestA
+--- FAIL: TestA (0.00s)
+`,
+			errorOutput: `    a_test.go:15:
+        	Error Trace:	a_test.go:15
+        	Error:      	Not equal:
+        	            	expected: 1
+        	            	actual  : 2
+        	Test:       	TestA
+`,
+			expected: failure{message: "Not equal:\nexpected: 1\nactual  : 2", file: "a_test.go", line: 15},
+		},
+		{
+			name: "testify failure block without output types",
+			output: `=== RUN   TestA
+    a_test.go:9: starting
+    a_test.go:15:
+        	Error Trace:	a_test.go:15
+        	Error:      	Should be true
+        	Test:       	TestA
+--- FAIL: TestA (0.00s)
+`,
+			expected: failure{message: "Should be true", file: "a_test.go", line: 15},
+		},
+		{
+			name: "panic after the result",
+			output: `=== RUN   TestA
+    a_test.go:9: before
+--- FAIL: TestA (0.00s)
+panic: boom [recovered]
+
+goroutine 8 [running]:
+testing.tRunner.func1.2({0x6b4308, 0x1ef33be600})
+	/usr/local/go/src/testing/testing.go:2123 +0x232
+example.com/a.TestA(0x1ef34386c8?)
+	/src/example.com/a/a_test.go:20 +0x86
+`,
+			expected: failure{message: "panic: boom [recovered]", file: "/src/example.com/a/a_test.go", line: 20},
+		},
+		{
+			name: "first line of other output",
+			output: `=== RUN   TestA
+something went wrong
+and more
+--- FAIL: TestA (0.00s)
+`,
+			expected: failure{message: "something went wrong"},
+		},
+		{
+			name:     "no output",
+			output:   "=== RUN   TestA\n--- FAIL: TestA (0.00s)\n",
+			expected: failure{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := parseFailure(testCase.output, testCase.errorOutput); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("did not parse the correct failure:\nexpected: %#v\ngot:      %#v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSubtestsFailureMessage(t *testing.T) {
+	failed := &api.FailureOutput{}
+	tests := []*api.TestCase{
+		{Name: "TestTable", FailureOutput: failed},
+		{Name: "TestTable/a", FailureOutput: failed},
+		{Name: "TestTable/a/deep", FailureOutput: failed},
+		{Name: "TestTable/b", FailureOutput: failed},
+		{Name: "TestTable/c"},
+		{Name: "TestTableOther/a", FailureOutput: failed},
+		{Name: "TestOther", FailureOutput: failed},
+	}
+
+	var testCases = []struct {
+		name     string
+		test     string
+		expected string
+	}{
+		{
+			name:     "direct subtests",
+			test:     "TestTable",
+			expected: "Subtests failed: TestTable/a, TestTable/b",
+		},
+		{
+			name:     "nested subtests",
+			test:     "TestTable/a",
+			expected: "Subtests failed: TestTable/a/deep",
+		},
+		{
+			name:     "no subtests",
+			test:     "TestOther",
+			expected: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := subtestsFailureMessage(testCase.test, tests); actual != testCase.expected {
+				t.Errorf("did not describe the failed subtests correctly:\nexpected: %q\ngot:      %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTestifyMessage(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		lines    string
+		expected string
+	}{
+		{
+			name: "error with continuation",
+			lines: `        	Error Trace:	a_test.go:15
+        	            				helpers_test.go:30
+        	Error:      	Not equal:
+        	            	expected: 1
+        	            	actual  : 2
+        	Test:       	TestA
+--- FAIL: TestA (0.00s)`,
+			expected: "Not equal:\nexpected: 1\nactual  : 2",
+		},
+		{
+			name: "error with messages",
+			lines: `        	Error:      	Should be true
+        	Test:       	TestA
+        	Messages:   	pod should be ready`,
+			expected: "Should be true",
+		},
+		{
+			name:     "no failure block",
+			lines:    "    a_test.go:16: other output",
+			expected: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := testifyMessage(strings.Split(testCase.lines, "\n")); actual != testCase.expected {
+				t.Errorf("did not find the correct testify message:\nexpected: %q\ngot:      %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStreamFailures(t *testing.T) {
+	expected := []string{
+		"example.com/fix/failures tests=3 failures=3 skipped=0",
+		"  TestLogThenError failed: expected 1, got 2",
+		"  TestPanic failed: panic: boom 1 [recovered, repanicked]",
+		"  TestTestify failed: Not equal:\nexpected: 1\nactual  : 2",
+		"go test tests=1 failures=0 skipped=0",
+		"  build and execution passed",
+	}
+
+	// older versions of go test do not report which lines of the output are errors
+	for _, name := range []string{"failures.json", "failures_untyped.json"} {
+		t.Run(name, func(t *testing.T) {
+			suites := streamTestSuites(t, name, options{})
+			if actual := describeSuites(suites.Suites); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("did not report the correct failures:\nexpected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+			}
+			if test := suites.Suites[0].TestCases[0]; test.File != "failures_test.go" || test.Line != 10 {
+				t.Errorf("expected the failure to be located at failures_test.go:10, got %s:%d", test.File, test.Line)
+			}
+		})
+	}
+}
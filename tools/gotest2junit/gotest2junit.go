@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/openshift/release/tools/gotest2junit/pkg/api"
 )
@@ -19,36 +20,134 @@ type Record struct {
 	Package string
 	Test    string
 
-	Time    time.Time
-	Action  string
-	Output  string
-	Elapsed float64
+	Time       time.Time
+	Action     string
+	Output     string
+	OutputType string
+	Elapsed    float64
 }
 
 type testSuite struct {
 	suite *api.TestSuite
 	tests map[string]*api.TestCase
+
+	// errors holds the output of every test that go test reported as errors, like that of t.Error
+	errors map[string]string
+
+	// states track when every test is running, to find the tests that did not complete
+	states map[string]*testState
+
+	// passed and failed record that the test binary of the package passed or failed
+	passed bool
+	failed bool
+
+	// panicked records that the test binary panicked, like when it timed out, after which output that is not
+	// attributed to a test belongs to the test that was running
+	panicked bool
+
+	// last is the time of the last record of the package
+	last time.Time
+
+	// output is the output of the package that is not written by any test, like that of TestMain
+	output string
+
+	// reported is the test whose result is being reported by the package, which is followed by the output of
+	// the test when it ran in parallel with others in older versions of go test
+	reported *api.TestCase
+}
+
+// testState tracks when a test is running, so that the time it spends paused by t.Parallel is not counted
+type testState struct {
+	// running is the time the test spent running until it was last paused
+	running time.Duration
+
+	// resumed is the time at which the test was last run or continued, which is zero while it is paused
+	resumed time.Time
+
+	// paused records that the test was paused at least once
+	paused bool
+
+	// concluded records that the test passed, failed or was skipped
+	concluded bool
 }
 
+// pause stops counting the time the test is running
+func (s *testState) pause(t time.Time) {
+	if !s.resumed.IsZero() && t.After(s.resumed) {
+		s.running += t.Sub(s.resumed)
+	}
+	s.resumed = time.Time{}
+}
+
+// elapsed returns the time the test spent running until the given time, rounded to the millisecond
+func (s *testState) elapsed(t time.Time) float64 {
+	running := s.running
+	if !s.resumed.IsZero() && t.After(s.resumed) {
+		running += t.Sub(s.resumed)
+	}
+	return float64(running.Milliseconds()) / 1000
+}
+
+// duration returns the duration of a concluded test. Go reports the duration of a test without the time it was
+// paused by t.Parallel, but older versions included it, so the time the test was seen running is used if it is
+// shorter.
+func (s *testState) duration(elapsed float64) float64 {
+	if running := float64(s.running.Milliseconds()) / 1000; s.paused && running < elapsed {
+		return running
+	}
+	return elapsed
+}
+
+// options determine how test output is reported
+type options struct {
+	// summarize displays a summary as items are processed
+	summarize bool
+	// verbose displays passing results in the summary
+	verbose bool
+	// subtests nests subtests in test suites for their parent tests
+	subtests bool
+	// passingOutput keeps the output of passing tests
+	passingOutput bool
+	// passingOutputLimit is the maximum number of bytes of output kept for every passing test, 0 for no limit
+	passingOutputLimit int
+	// timestamps records when every test suite and test started
+	timestamps bool
+}
+
+// timestampFormat is the format of the times at which test suites and tests started, which is ISO 8601 with the
+// precision needed to order tests that run in quick succession
+const timestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// truncationMarker is appended to output that is truncated, with the number of bytes removed
+const truncationMarker = "\n... [truncated %d bytes]"
+
 func main() {
-	summarize := false
-	verbose := false
-	flag.BoolVar(&summarize, "summary", true, "display a summary as items are processed")
-	flag.BoolVar(&verbose, "v", false, "display passing results")
+	var opts options
+	flag.BoolVar(&opts.summarize, "summary", true, "display a summary as items are processed")
+	flag.BoolVar(&opts.verbose, "v", false, "display passing results")
+	flag.BoolVar(&opts.subtests, "subtests", false, "nest subtests in test suites for their parent tests, reporting every failure once")
+	flag.BoolVar(&opts.passingOutput, "passing-output", false, "keep the output of passing tests")
+	flag.IntVar(&opts.passingOutputLimit, "passing-output-limit", 0, "the maximum number of bytes of output kept for every passing test, larger output is truncated; 0 for no limit")
+	flag.BoolVar(&opts.timestamps, "timestamps", false, "record when every test suite and test started")
 	flag.Parse()
 
-	if err := process(os.Stdin, summarize, verbose); err != nil {
+	if opts.passingOutputLimit < 0 {
+		fmt.Fprintf(os.Stderr, "error: the passing output limit must not be negative, got %d\n", opts.passingOutputLimit)
+		os.Exit(1)
+	}
+
+	if err := process(os.Stdin, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func process(r io.Reader, summarize, verbose bool) error {
-	suites, err := stream(r, summarize, verbose)
+func process(r io.Reader, opts options) error {
+	suites, err := stream(r, opts)
 	if err != nil {
 		return err
 	}
-	obj := newTestSuites(suites)
+	obj := newTestSuites(suites, opts.subtests)
 	out, err := xml.MarshalIndent(obj, "", "  ")
 	if err != nil {
 		return err
@@ -57,28 +156,19 @@ func process(r io.Reader, summarize, verbose bool) error {
 	return nil
 }
 
-func newTestSuites(suites map[string]*testSuite) *api.TestSuites {
+func newTestSuites(suites map[string]*testSuite, subtests bool) *api.TestSuites {
 	all := &api.TestSuites{}
 	for _, suite := range suites {
-		for _, test := range suite.suite.TestCases {
-			suite.suite.NumTests++
-			if test.SkipMessage != nil {
-				suite.suite.NumSkipped++
-				continue
-			}
-			if test.FailureOutput != nil {
-				suite.suite.NumFailed++
-				continue
-			}
+		if subtests {
+			nestSubtests(suite.suite)
 		}
+		countTests(suite.suite)
 		// suites with no tests are usually empty packages, ignore them
 		if suite.suite.NumTests == 0 {
 			continue
 		}
 		// always return the test cases in consistent order
-		sort.Slice(suite.suite.TestCases, func(i, j int) bool {
-			return suite.suite.TestCases[i].Name < suite.suite.TestCases[j].Name
-		})
+		sortTests(suite.suite)
 		all.Suites = append(all.Suites, suite.suite)
 	}
 	// always return the test suites in consistent order
@@ -88,7 +178,7 @@ func newTestSuites(suites map[string]*testSuite) *api.TestSuites {
 	return all
 }
 
-func stream(r io.Reader, summarize, verbose bool) (map[string]*testSuite, error) {
+func stream(r io.Reader, opts options) (map[string]*testSuite, error) {
 	suites := make(map[string]*testSuite)
 	defaultTest := &api.TestCase{
 		Name: "build and execution",
@@ -132,16 +222,31 @@ func stream(r io.Reader, summarize, verbose bool) (map[string]*testSuite, error)
 				suite: &api.TestSuite{
 					Name: r.Package,
 				},
-				tests: make(map[string]*api.TestCase),
+				tests:  make(map[string]*api.TestCase),
+				errors: make(map[string]string),
+				states: make(map[string]*testState),
+			}
+			// the first record of a package is written when its test binary starts
+			if opts.timestamps && !r.Time.IsZero() {
+				suite.suite.Timestamp = r.Time.Format(timestampFormat)
 			}
 			suites[r.Package] = suite
 		}
+		if !r.Time.IsZero() {
+			suite.last = r.Time
+		}
 
-		// if this is package level output, we only care about pass/fail duration
+		// if this is package level output, we only care about pass/fail duration and output not written by go test
 		if len(r.Test) == 0 {
 			switch r.Action {
-			case "pass", "fail":
+			case "pass":
+				suite.passed = true
+				suite.suite.Duration = r.Elapsed
+			case "fail":
+				suite.failed = true
 				suite.suite.Duration = r.Elapsed
+			case "output":
+				suite.addOutput(r.Output)
 			}
 			continue
 		}
@@ -151,45 +256,63 @@ func stream(r io.Reader, summarize, verbose bool) (map[string]*testSuite, error)
 			test = &api.TestCase{
 				Name: r.Test,
 			}
+			if opts.timestamps && !r.Time.IsZero() {
+				test.Timestamp = r.Time.Format(timestampFormat)
+			}
 			suite.suite.TestCases = append(suite.suite.TestCases, test)
 			suite.tests[r.Test] = test
+			suite.states[r.Test] = &testState{resumed: r.Time}
+		}
+		state := suite.states[r.Test]
+
+		switch r.Action {
+		case "bench", "skip", "pass", "fail":
+			state.pause(r.Time)
+			state.concluded = true
 		}
 
 		switch r.Action {
 		case "run":
 		case "pause":
+			state.pause(r.Time)
+			state.paused = true
 		case "cont":
+			state.resumed = r.Time
 		case "bench":
 		case "skip":
-			if summarize {
+			if opts.summarize {
 				fmt.Fprintf(os.Stderr, "SKIP: %s %s\n", r.Package, r.Test)
 			}
 			test.SkipMessage = &api.SkipMessage{
 				Message: r.Output,
 			}
 		case "pass":
-			if summarize && verbose {
+			if opts.summarize && opts.verbose {
 				fmt.Fprintf(os.Stderr, "PASS: %s %s %s\n", r.Package, r.Test, time.Duration(r.Elapsed*float64(time.Second)))
 			}
-			test.SystemOut = ""
-			test.Duration = r.Elapsed
+			test.SystemOut = opts.passingTestOutput(test.SystemOut)
+			test.Duration = state.duration(r.Elapsed)
 		case "fail":
-			if summarize {
+			if opts.summarize {
 				fmt.Fprintf(os.Stderr, "FAIL: %s %s %s\n", r.Package, r.Test, time.Duration(r.Elapsed*float64(time.Second)))
 			}
-			test.Duration = r.Elapsed
-			if len(r.Output) == 0 {
-				r.Output = test.SystemOut
-				if len(r.Output) > 50 {
-					r.Output = r.Output[:50] + " ..."
-				}
+			test.Duration = state.duration(r.Elapsed)
+			// the full output of the test is the body of the failure, so it is not repeated as system output
+			f := parseFailure(test.SystemOut, suite.errors[r.Test])
+			if len(f.message) == 0 {
+				f.message = subtestsFailureMessage(r.Test, suite.suite.TestCases)
 			}
+			test.File, test.Line = f.file, f.line
 			test.FailureOutput = &api.FailureOutput{
-				Message: r.Output,
-				Output:  r.Output,
+				Message: f.message,
+				Output:  test.SystemOut,
 			}
+			test.SystemOut = ""
 		case "output":
 			test.SystemOut += r.Output
+			if r.OutputType == "error" || r.OutputType == "error-continue" {
+				suite.errors[r.Test] += r.Output
+			}
 		default:
 			// usually a bug in go test -json
 			out := fmt.Sprintf("error: Unrecognized go test action %s: %#v\n", r.Action, r)
@@ -200,6 +323,15 @@ func stream(r io.Reader, summarize, verbose bool) (map[string]*testSuite, error)
 		}
 	}
 
+	for _, suite := range suites {
+		// the default suite holds no tests that are run
+		if suite == defaultSuite {
+			continue
+		}
+		suite.failIncompleteTests(opts.summarize)
+		suite.addPackageTest(opts)
+	}
+
 	// if we recorded any failure output
 	if defaultTest.FailureOutput != nil {
 		defaultTest.FailureOutput.Message = "Some packages failed during test execution"
@@ -209,3 +341,142 @@ func stream(r io.Reader, summarize, verbose bool) (map[string]*testSuite, error)
 
 	return suites, nil
 }
+
+// runningTest returns the test that started last of those that are running, if any
+func (s *testSuite) runningTest() *api.TestCase {
+	for i := len(s.suite.TestCases) - 1; i >= 0; i-- {
+		test := s.suite.TestCases[i]
+		if state := s.states[test.Name]; !state.concluded && !state.resumed.IsZero() {
+			return test
+		}
+	}
+	return nil
+}
+
+// addOutput records output of the package that go test did not attribute to a test. Older versions of go test
+// write the output of a panic and the output of tests that ran in parallel after the result of the test, without
+// attributing it, so such output is attributed to the test that was running or whose result was reported. Other
+// output, like that of TestMain, is recorded for the package, except for the lines go test writes for every
+// package.
+func (s *testSuite) addOutput(output string) {
+	if strings.HasPrefix(output, "panic: ") {
+		s.panicked = true
+	}
+	if s.panicked {
+		if test := s.runningTest(); test != nil {
+			test.SystemOut += output
+			return
+		}
+	}
+
+	if m := testResultPattern.FindStringSubmatch(output); m != nil {
+		s.reported = s.tests[m[1]]
+		return
+	}
+	if s.reported != nil && (strings.HasPrefix(output, " ") || strings.HasPrefix(output, "\t")) {
+		// the output of a passing test is not kept once it has passed
+		if test := s.reported; test.FailureOutput != nil {
+			test.FailureOutput.Output += output
+			// the failure may only be found in the output written after the result
+			if len(test.File) == 0 {
+				f := parseFailure(test.FailureOutput.Output, "")
+				test.FailureOutput.Message, test.File, test.Line = f.message, f.file, f.line
+			}
+		}
+		return
+	}
+	s.reported = nil
+
+	if packageFramePattern.MatchString(strings.TrimRight(output, "\n")) {
+		return
+	}
+	s.output += output
+}
+
+// failIncompleteTests marks the tests that started but never passed, failed or were skipped as failed, which
+// happens when the test binary times out, panics or is killed before its package passes. The duration of such a
+// test is the time it was running until the last record of its package. Benchmarks do not conclude on their own,
+// so they are only considered incomplete if they did not report a result.
+func (s *testSuite) failIncompleteTests(summarize bool) {
+	if s.passed {
+		return
+	}
+	for _, test := range s.suite.TestCases {
+		state := s.states[test.Name]
+		if state.concluded {
+			continue
+		}
+		if strings.HasPrefix(test.Name, "Benchmark") && benchmarkResultPattern.MatchString(test.SystemOut) {
+			continue
+		}
+
+		test.Duration = state.elapsed(s.last)
+		message := incompleteTestMessage
+		if reason := panicMessage(test.SystemOut); len(reason) > 0 {
+			message = fmt.Sprintf("%s: %s", incompleteTestMessage, reason)
+		}
+		if summarize {
+			fmt.Fprintf(os.Stderr, "FAIL: %s %s did not complete\n", s.suite.Name, test.Name)
+		}
+		test.FailureOutput = &api.FailureOutput{
+			Message: message,
+			Output:  test.SystemOut,
+		}
+		test.SystemOut = ""
+	}
+}
+
+// addPackageTest adds a failing test case for the package itself if it failed although none of its tests failed,
+// like when TestMain fails. If the output of passing tests is kept, a passing test case is added for a package that
+// passed but wrote output outside of its tests, so that the output is kept as well.
+func (s *testSuite) addPackageTest(opts options) {
+	failed := s.failed
+	for _, test := range s.suite.TestCases {
+		if test.FailureOutput != nil {
+			failed = false
+			break
+		}
+	}
+	if !failed && len(opts.passingTestOutput(s.output)) == 0 {
+		return
+	}
+
+	test := &api.TestCase{
+		Name:      packageTestName,
+		Timestamp: s.suite.Timestamp,
+	}
+	if failed {
+		if opts.summarize {
+			fmt.Fprintf(os.Stderr, "FAIL: %s outside of its tests\n", s.suite.Name)
+		}
+		test.FailureOutput = &api.FailureOutput{
+			Message: packageFailureMessage,
+			Output:  s.output,
+		}
+	} else {
+		test.SystemOut = opts.passingTestOutput(s.output)
+	}
+	s.suite.TestCases = append(s.suite.TestCases, test)
+}
+
+// passingTestOutput returns the output kept for a passing test, which is none unless the output of passing tests
+// is kept
+func (o options) passingTestOutput(output string) string {
+	if !o.passingOutput {
+		return ""
+	}
+	return truncate(output, o.passingOutputLimit)
+}
+
+// truncate cuts the output to the limit in bytes without cutting a multi-byte character in half, noting how much
+// was removed. A limit of 0 keeps all of the output.
+func truncate(output string, limit int) string {
+	if limit == 0 || len(output) <= limit {
+		return output
+	}
+	end := limit
+	for end > 0 && !utf8.RuneStart(output[end]) {
+		end--
+	}
+	return output[:end] + fmt.Sprintf(truncationMarker, len(output)-end)
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/release/tools/gotest2junit/pkg/api"
+)
+
+// streamTestSuites reports the test suites for a go test -json stream recorded in testdata
+func streamTestSuites(t *testing.T, name string, opts options) *api.TestSuites {
+	t.Helper()
+	input, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("unexpected error opening recorded test output: %v", err)
+	}
+	defer input.Close()
+
+	suites, err := stream(input, opts)
+	if err != nil {
+		t.Fatalf("unexpected error reading recorded test output: %v", err)
+	}
+	return newTestSuites(suites, opts.subtests)
+}
+
+// describeSuites describes the test suites, the suites nested under them and their test cases with one line
+// each, in order for tests to compare the structure and results of reports without their output
+func describeSuites(suites []*api.TestSuite) []string {
+	var lines []string
+	for _, suite := range suites {
+		lines = append(lines, describeSuite(suite, "")...)
+	}
+	return lines
+}
+
+func describeSuite(suite *api.TestSuite, indent string) []string {
+	lines := []string{fmt.Sprintf("%s%s tests=%d failures=%d skipped=%d", indent, suite.Name, suite.NumTests, suite.NumFailed, suite.NumSkipped)}
+	for _, test := range suite.TestCases {
+		result := "passed"
+		switch {
+		case test.SkipMessage != nil:
+			result = "skipped"
+		case test.FailureOutput != nil:
+			result = fmt.Sprintf("failed: %s", test.FailureOutput.Message)
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s %s", indent, test.Name, result))
+	}
+	for _, child := range suite.Children {
+		lines = append(lines, describeSuite(child, indent+"  ")...)
+	}
+	return lines
+}
+
+func TestStreamPassingOutput(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		opts           options
+		expectedChatty string
+		expectedQuiet  string
+	}{
+		{
+			name: "dropped",
+			opts: options{},
+		},
+		{
+			name:           "kept",
+			opts:           options{passingOutput: true},
+			expectedChatty: "=== RUN   TestChatty\n    passing_test.go:6: héllo wörld\n--- PASS: TestChatty (0.00s)\n",
+			expectedQuiet:  "=== RUN   TestQuiet\n--- PASS: TestQuiet (0.00s)\n",
+		},
+		{
+			name:           "truncated",
+			opts:           options{passingOutput: true, passingOutputLimit: 46},
+			expectedChatty: "=== RUN   TestChatty\n    passing_test.go:6: h\n... [truncated 42 bytes]",
+			expectedQuiet:  "=== RUN   TestQuiet\n--- PASS: TestQuiet (0.00s\n... [truncated 2 bytes]",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			suites := streamTestSuites(t, "passing.json", testCase.opts)
+			tests := suites.Suites[0].TestCases
+			if tests[0].SystemOut != testCase.expectedChatty {
+				t.Errorf("did not keep the correct output of TestChatty:\nexpected: %q\ngot:      %q", testCase.expectedChatty, tests[0].SystemOut)
+			}
+			if tests[1].SystemOut != testCase.expectedQuiet {
+				t.Errorf("did not keep the correct output of TestQuiet:\nexpected: %q\ngot:      %q", testCase.expectedQuiet, tests[1].SystemOut)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		output   string
+		limit    int
+		expected string
+	}{
+		{
+			name:     "no limit",
+			output:   "output",
+			expected: "output",
+		},
+		{
+			name:     "shorter than the limit",
+			output:   "output",
+			limit:    10,
+			expected: "output",
+		},
+		{
+			name:     "as long as the limit",
+			output:   "output",
+			limit:    6,
+			expected: "output",
+		},
+		{
+			name:     "longer than the limit",
+			output:   "output",
+			limit:    3,
+			expected: "out\n... [truncated 3 bytes]",
+		},
+		{
+			name:     "limit in a multi-byte character",
+			output:   "wörld",
+			limit:    2,
+			expected: "w\n... [truncated 5 bytes]",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := truncate(testCase.output, testCase.limit); actual != testCase.expected {
+				t.Errorf("did not truncate correctly:\nexpected: %q\ngot:      %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStreamTimestamps(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		timestamps         bool
+		expectedSuite      string
+		expectedTestChatty string
+	}{
+		{
+			name: "not recorded",
+		},
+		{
+			name:               "recorded",
+			timestamps:         true,
+			expectedSuite:      "2026-10-18T02:49:18.098Z",
+			expectedTestChatty: "2026-10-18T02:49:18.101Z",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			suite := streamTestSuites(t, "passing.json", options{timestamps: testCase.timestamps}).Suites[0]
+			if suite.Timestamp != testCase.expectedSuite {
+				t.Errorf("expected the suite to start at %q, got %q", testCase.expectedSuite, suite.Timestamp)
+			}
+			if test := suite.TestCases[0]; test.Timestamp != testCase.expectedTestChatty {
+				t.Errorf("expected TestChatty to start at %q, got %q", testCase.expectedTestChatty, test.Timestamp)
+			}
+		})
+	}
+}
+
+func TestFailIncompleteTests(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		input             string
+		expected          []string
+		expectedDurations map[string]float64
+	}{
+		{
+			name:  "timed out",
+			input: "timeout.json",
+			expected: []string{
+				"example.com/fix/timeout tests=3 failures=2 skipped=0",
+				"  TestHangs failed: Test did not complete",
+				"  TestHangs/inner failed: Test did not complete: panic: test timed out after 1s",
+				"  TestQuick passed",
+			},
+			expectedDurations: map[string]float64{"TestHangs": 1.003, "TestHangs/inner": 1.003, "TestQuick": 0},
+		},
+		{
+			name:  "timed out without the panic attributed to the test",
+			input: "timeout_unattributed.json",
+			expected: []string{
+				"example.com/fix/timeout tests=3 failures=2 skipped=0",
+				"  TestHangs failed: Test did not complete",
+				"  TestHangs/inner failed: Test did not complete: panic: test timed out after 1s",
+				"  TestQuick passed",
+			},
+			expectedDurations: map[string]float64{"TestHangs": 1.003, "TestHangs/inner": 1.003, "TestQuick": 0},
+		},
+		{
+			name:  "killed",
+			input: "passing_killed.json",
+			expected: []string{
+				"example.com/fix/passing tests=2 failures=1 skipped=0",
+				"  TestChatty passed",
+				"  TestQuiet failed: Test did not complete",
+			},
+			expectedDurations: map[string]float64{"TestChatty": 0, "TestQuiet": 0},
+		},
+		{
+			name:  "killed while benchmarking",
+			input: "bench_killed.json",
+			expected: []string{
+				"example.com/sample/bench tests=2 failures=1 skipped=0",
+				"  BenchmarkLogs failed: Test did not complete",
+				"  BenchmarkQuiet passed",
+			},
+			expectedDurations: map[string]float64{"BenchmarkLogs": 0, "BenchmarkQuiet": 0},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			suite := streamTestSuites(t, testCase.input, options{}).Suites[0]
+			if actual := describeSuite(suite, ""); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("did not report the correct test suite:\nexpected:\n%s\ngot:\n%s", strings.Join(testCase.expected, "\n"), strings.Join(actual, "\n"))
+			}
+			durations := map[string]float64{}
+			for _, test := range suite.TestCases {
+				durations[test.Name] = test.Duration
+			}
+			if !reflect.DeepEqual(durations, testCase.expectedDurations) {
+				t.Errorf("did not report the correct durations:\nexpected: %v\ngot:      %v", testCase.expectedDurations, durations)
+			}
+		})
+	}
+}
+
+func TestStreamPausedTests(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		input             string
+		expected          []string
+		expectedDurations map[string]float64
+	}{
+		{
+			name:  "elapsed time without pauses",
+			input: "parallel.json",
+			expected: []string{
+				"example.com/fix/parallel tests=3 failures=0 skipped=0",
+				"  TestA passed",
+				"  TestB passed",
+				"  TestSerial passed",
+			},
+			expectedDurations: map[string]float64{"TestA": 0.2, "TestB": 0.2, "TestSerial": 0.1},
+		},
+		{
+			// older versions of go test include the time tests were paused by t.Parallel in their elapsed time
+			name:  "elapsed time with pauses",
+			input: "parallel_paused_elapsed.json",
+			expected: []string{
+				"example.com/fix/parallel tests=3 failures=0 skipped=0",
+				"  TestA passed",
+				"  TestB passed",
+				"  TestSerial passed",
+			},
+			expectedDurations: map[string]float64{"TestA": 0.2, "TestB": 0.201, "TestSerial": 0.1},
+		},
+		{
+			// older versions of go test also write the output of parallel tests after their result
+			name:  "output after the result",
+			input: "parallel_result_first.json",
+			expected: []string{
+				"example.com/fix/parallel tests=1 failures=1 skipped=0",
+				"  TestA failed: a failed",
+			},
+			expectedDurations: map[string]float64{"TestA": 0.3},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			suite := streamTestSuites(t, testCase.input, options{}).Suites[0]
+			if actual := describeSuite(suite, ""); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("did not report the correct test suite:\nexpected:\n%s\ngot:\n%s", strings.Join(testCase.expected, "\n"), strings.Join(actual, "\n"))
+			}
+			durations := map[string]float64{}
+			for _, test := range suite.TestCases {
+				durations[test.Name] = test.Duration
+			}
+			if !reflect.DeepEqual(durations, testCase.expectedDurations) {
+				t.Errorf("did not report the correct durations:\nexpected: %v\ngot:      %v", testCase.expectedDurations, durations)
+			}
+		})
+	}
+}
+
+func TestAddPackageTest(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		input          string
+		passingOutput  bool
+		expected       []string
+		expectedOutput string
+	}{
+		{
+			name:  "passed with output",
+			input: "testmain.json",
+			expected: []string{
+				"example.com/fix/testmain tests=1 failures=0 skipped=0",
+				"  TestOne passed",
+			},
+		},
+		{
+			name:          "passed with output kept",
+			input:         "testmain.json",
+			passingOutput: true,
+			expected: []string{
+				"example.com/fix/testmain tests=2 failures=0 skipped=0",
+				"  TestOne passed",
+				"  package output passed",
+			},
+			expectedOutput: "setting up\ntearing down\n",
+		},
+		{
+			name:  "failed outside of its tests",
+			input: "testmain_fail.json",
+			expected: []string{
+				"example.com/fix/testmain tests=2 failures=1 skipped=0",
+				"  TestOne passed",
+				"  package output failed: Package failed outside of its tests",
+			},
+			expectedOutput: "setting up\ntearing down\nteardown failed\n",
+		},
+		{
+			name:  "failed in its tests",
+			input: "failures.json",
+			expected: []string{
+				"example.com/fix/failures tests=3 failures=3 skipped=0",
+				"  TestLogThenError failed: expected 1, got 2",
+				"  TestPanic failed: panic: boom 1 [recovered, repanicked]",
+				"  TestTestify failed: Not equal:\nexpected: 1\nactual  : 2",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			suite := streamTestSuites(t, testCase.input, options{passingOutput: testCase.passingOutput}).Suites[0]
+			if actual := describeSuite(suite, ""); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("did not report the correct test suite:\nexpected:\n%s\ngot:\n%s", strings.Join(testCase.expected, "\n"), strings.Join(actual, "\n"))
+			}
+			var output string
+			for _, test := range suite.TestCases {
+				if test.Name != packageTestName {
+					continue
+				}
+				output = test.SystemOut
+				if test.FailureOutput != nil {
+					output = test.FailureOutput.Output
+				}
+			}
+			if output != testCase.expectedOutput {
+				t.Errorf("did not keep the correct package output:\nexpected: %q\ngot:      %q", testCase.expectedOutput, output)
+			}
+		})
+	}
+}
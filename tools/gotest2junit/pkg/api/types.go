@@ -35,6 +35,9 @@ type TestSuite struct {
 	// Duration is the time taken in seconds to run all tests in the suite
 	Duration float64 `xml:"time,attr"`
 
+	// Timestamp is the time at which the suite started, in ISO 8601 format, if known
+	Timestamp string `xml:"timestamp,attr,omitempty"`
+
 	// Properties holds other properties of the test suite as a mapping of name to value
 	Properties []*TestSuiteProperty `xml:"properties,omitempty"`
 
@@ -66,6 +69,15 @@ type TestCase struct {
 	// Duration is the time taken in seconds to run the test
 	Duration float64 `xml:"time,attr"`
 
+	// Timestamp is the time at which the test started, in ISO 8601 format, if known
+	Timestamp string `xml:"timestamp,attr,omitempty"`
+
+	// File is the source file in which the test failed, as reported by go test, if known
+	File string `xml:"file,attr,omitempty"`
+
+	// Line is the line in the source file at which the test failed, if known
+	Line int `xml:"line,attr,omitempty"`
+
 	// SkipMessage holds the reason why the test was skipped
 	SkipMessage *SkipMessage `xml:"skipped"`
 
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/openshift/release/tools/gotest2junit/pkg/api"
+)
+
+// subtestNameDelimiter is the delimiter between the names of parent tests and their subtests
+const subtestNameDelimiter = "/"
+
+// testNode is a test case in the hierarchy of tests and subtests of a package
+type testNode struct {
+	test *api.TestCase
+
+	// children are the subtests of this test, in the order in which they were run
+	children []*testNode
+}
+
+// nestSubtests replaces the test cases of tests that have subtests with child suites of the package suite. The
+// child suite of a test is named after the package and the full name of the test, holds the subtests of the test
+// and takes the duration of the test. Go fails a test when any of its subtests fail, so a failing parent test is
+// only kept as a test case in its own suite if none of its subtests failed, in order for every failure to be
+// reported once, against the test that actually failed.
+func nestSubtests(suite *api.TestSuite) {
+	roots := buildTestTree(suite.TestCases)
+	suite.TestCases = nil
+	addTestNodes(suite, suite.Name, roots)
+}
+
+// buildTestTree arranges test cases under their closest ancestor test, keeping the order in which they were run
+func buildTestTree(tests []*api.TestCase) []*testNode {
+	nodes := make(map[string]*testNode)
+	for _, test := range tests {
+		nodes[test.Name] = &testNode{test: test}
+	}
+
+	var roots []*testNode
+	for _, test := range tests {
+		node := nodes[test.Name]
+		if parent := findParent(test.Name, nodes); parent != nil {
+			parent.children = append(parent.children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// findParent returns the closest ancestor of the named test, if one was recorded
+func findParent(name string, nodes map[string]*testNode) *testNode {
+	for {
+		i := strings.LastIndex(name, subtestNameDelimiter)
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+		if parent, ok := nodes[name]; ok {
+			return parent
+		}
+	}
+}
+
+// addTestNodes adds test nodes to the suite, turning those that have subtests into child suites
+func addTestNodes(suite *api.TestSuite, packageName string, nodes []*testNode) {
+	for _, node := range nodes {
+		if len(node.children) == 0 {
+			suite.TestCases = append(suite.TestCases, node.test)
+			continue
+		}
+
+		child := &api.TestSuite{
+			Name:      packageName + subtestNameDelimiter + node.test.Name,
+			Duration:  node.test.Duration,
+			Timestamp: node.test.Timestamp,
+		}
+		addTestNodes(child, packageName, node.children)
+
+		switch {
+		case node.test.FailureOutput != nil && !hasFailures(child):
+			// the parent test failed on its own account, so its failure is not recorded elsewhere
+			child.TestCases = append(child.TestCases, node.test)
+		case node.test.SkipMessage != nil:
+			child.TestCases = append(child.TestCases, node.test)
+		}
+
+		suite.Children = append(suite.Children, child)
+	}
+}
+
+// hasFailures determines if any test in the suite or the suites nested under it failed
+func hasFailures(suite *api.TestSuite) bool {
+	for _, test := range suite.TestCases {
+		if test.FailureOutput != nil {
+			return true
+		}
+	}
+	for _, child := range suite.Children {
+		if hasFailures(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// countTests records the number of tests, skipped tests and failed tests in the suite, including those in the
+// suites nested under it
+func countTests(suite *api.TestSuite) {
+	suite.NumTests, suite.NumSkipped, suite.NumFailed = 0, 0, 0
+	for _, test := range suite.TestCases {
+		suite.NumTests++
+		if test.SkipMessage != nil {
+			suite.NumSkipped++
+			continue
+		}
+		if test.FailureOutput != nil {
+			suite.NumFailed++
+			continue
+		}
+	}
+	for _, child := range suite.Children {
+		countTests(child)
+		suite.NumTests += child.NumTests
+		suite.NumSkipped += child.NumSkipped
+		suite.NumFailed += child.NumFailed
+	}
+}
+
+// sortTests orders the test cases and the nested suites of the suite by name
+func sortTests(suite *api.TestSuite) {
+	sort.Slice(suite.TestCases, func(i, j int) bool {
+		return suite.TestCases[i].Name < suite.TestCases[j].Name
+	})
+	sort.Slice(suite.Children, func(i, j int) bool {
+		return suite.Children[i].Name < suite.Children[j].Name
+	})
+	for _, child := range suite.Children {
+		sortTests(child)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/release/tools/gotest2junit/pkg/api"
+)
+
+func TestNestSubtests(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		subtests bool
+		expected []string
+	}{
+		{
+			name: "flat",
+			expected: []string{
+				"example.com/fix/subtests tests=8 failures=4 skipped=1",
+				"  TestNested failed: Subtests failed: TestNested/fail",
+				"  TestNested/fail failed: Subtests failed: TestNested/fail/deep",
+				"  TestNested/fail/deep failed: deep failure",
+				"  TestNested/pass passed",
+				"  TestNested/skip skipped",
+				"  TestParentFails failed: parent failure",
+				"  TestParentFails/pass passed",
+				"  TestPlain passed",
+				"go test tests=1 failures=0 skipped=0",
+				"  build and execution passed",
+			},
+		},
+		{
+			name:     "nested",
+			subtests: true,
+			expected: []string{
+				"example.com/fix/subtests tests=6 failures=2 skipped=1",
+				"  TestPlain passed",
+				"  example.com/fix/subtests/TestNested tests=3 failures=1 skipped=1",
+				"    TestNested/pass passed",
+				"    TestNested/skip skipped",
+				"    example.com/fix/subtests/TestNested/fail tests=1 failures=1 skipped=0",
+				"      TestNested/fail/deep failed: deep failure",
+				"  example.com/fix/subtests/TestParentFails tests=2 failures=1 skipped=0",
+				"    TestParentFails failed: parent failure",
+				"    TestParentFails/pass passed",
+				"go test tests=1 failures=0 skipped=0",
+				"  build and execution passed",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			suites := streamTestSuites(t, "subtests.json", options{subtests: testCase.subtests})
+			if actual := describeSuites(suites.Suites); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("did not report the correct test suites:\nexpected:\n%s\ngot:\n%s", strings.Join(testCase.expected, "\n"), strings.Join(actual, "\n"))
+			}
+		})
+	}
+}
+
+func TestBuildTestTree(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		tests    []string
+		expected []string
+	}{
+		{
+			name:     "no subtests",
+			tests:    []string{"TestB", "TestA"},
+			expected: []string{"TestB", "TestA"},
+		},
+		{
+			name:     "subtests in the order they ran",
+			tests:    []string{"TestA", "TestA/two", "TestA/one", "TestA/one/deep", "TestB"},
+			expected: []string{"TestA", "  TestA/two", "  TestA/one", "    TestA/one/deep", "TestB"},
+		},
+		{
+			name:     "missing parent",
+			tests:    []string{"TestA", "TestA/one/deep", "TestB/one"},
+			expected: []string{"TestA", "  TestA/one/deep", "TestB/one"},
+		},
+		{
+			name:     "parent with a delimiter in its name",
+			tests:    []string{"TestA", "TestA/a/b", "TestA/a/b/c"},
+			expected: []string{"TestA", "  TestA/a/b", "    TestA/a/b/c"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var tests []*api.TestCase
+			for _, name := range testCase.tests {
+				tests = append(tests, &api.TestCase{Name: name})
+			}
+			if actual := describeTestNodes(buildTestTree(tests), ""); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("did not build the correct test tree:\nexpected:\n%s\ngot:\n%s", strings.Join(testCase.expected, "\n"), strings.Join(actual, "\n"))
+			}
+		})
+	}
+}
+
+// describeTestNodes describes the test nodes and their children with one indented line each
+func describeTestNodes(nodes []*testNode, indent string) []string {
+	var lines []string
+	for _, node := range nodes {
+		lines = append(lines, indent+node.test.Name)
+		lines = append(lines, describeTestNodes(node.children, indent+"  ")...)
+	}
+	return lines
+}
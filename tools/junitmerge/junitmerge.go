@@ -10,6 +10,7 @@ import (
 
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/openshift/release/tools/junitreport/pkg/api"
 )
@@ -18,8 +19,9 @@ type uniqueSuites map[string]*suiteRuns
 
 func (s uniqueSuites) Merge(namePrefix string, suite *api.TestSuite) {
 	name := suite.Name
-	if len(namePrefix) > 0 {
-		name = namePrefix + "/"
+	// nested suites are usually named after their full path already, like the suites for go subtests
+	if len(namePrefix) > 0 && !strings.HasPrefix(name, namePrefix+"/") {
+		name = namePrefix + "/" + name
 	}
 	existing, ok := s[name]
 	if !ok {
@@ -59,7 +61,7 @@ func (r *suiteRuns) Merge(testCases []*api.TestCase) {
 		case existing.SkipMessage != nil && testCase.SkipMessage == nil:
 			// always replace a skip with a non-skip
 			r.runs[testCase.Name] = testCase
-		case existing.FailureOutput == nil && testCase.FailureOutput != nil:
+		case existing.FailureOutput == nil && existing.ErrorOutput == nil && (testCase.FailureOutput != nil || testCase.ErrorOutput != nil):
 			// replace a passing test with a failing test
 			r.runs[testCase.Name] = testCase
 		}
@@ -167,6 +169,11 @@ func main() {
 				out.NumSkipped++
 			case testCase.FailureOutput != nil:
 				out.NumFailed++
+			case testCase.ErrorOutput != nil:
+				out.NumErrored++
+			}
+			if len(testCase.FlakyFailures) > 0 {
+				out.NumFlaky++
 			}
 			out.Duration += testCase.Duration
 		}
@@ -201,7 +208,7 @@ func summaryFor(suites *api.TestSuites) *SuiteSummary {
 			}
 			s.Tests = append(s.Tests, TestCaseSummary{
 				Name:   testCase.Name,
-				Failed: testCase.FailureOutput != nil,
+				Failed: testCase.FailureOutput != nil || testCase.ErrorOutput != nil,
 				Time:   testCase.Duration,
 			})
 		}
@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"text/tabwriter"
 
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
 	"github.com/openshift/origin/tools/junitreport/pkg/cmd"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser"
 )
 
 var (
@@ -20,46 +25,85 @@ var (
 	// rootSuites is a flag that holds the comma-delimited list of root suite names
 	rootSuites string
 
-	// testOutputFile is a flag that holds the path to the file containing test output
-	testOutputFile string
+	// parserConfig is a flag that holds the path to the configuration of the parser, for types that need one
+	parserConfig string
+
+	// testOutputFiles is a flag that holds the paths or glob patterns of the files containing test output
+	testOutputFiles stringSlice
+
+	// namespaceSuites is a flag that determines if test suites should be namespaced by the file they are parsed from
+	namespaceSuites bool
 
 	// outputFile is a flag that holds the path to the jUnit XML report to be written
 	outputFile string
 
+	// nestSubtests is a flag that determines if `go test` subtests should be nested under their parent tests
+	nestSubtests bool
+
 	// stream is a flag that determines if a streamed subset of the input stream should be printed as it is read
 	stream bool
+
+	// incrementalOutput is a flag that determines if test suites should be written as soon as they are complete
+	incrementalOutput bool
+
+	// redactions is a flag that holds the regular expressions matching test output to be redacted
+	redactions stringSlice
+
+	// maxOutputBytes is a flag that holds the maximum size of every output of a test case
+	maxOutputBytes int
+
+	// includeTests is a flag that holds the regular expressions matching the names of test cases to report
+	includeTests stringSlice
+
+	// excludeTests is a flag that holds the regular expressions matching the names of test cases not to report
+	excludeTests stringSlice
 )
 
 const (
-	defaultParserType     = "gotest"
-	defaultBuilderType    = "flat"
-	defaultTestOutputFile = "/dev/stdin"
-	defaultOutputFile     = "/dev/stdout"
-	defaultFilter         = false
+	defaultParserType  = "gotest"
+	defaultBuilderType = "flat"
+	defaultOutputFile  = "/dev/stdout"
+	defaultFilter      = false
 )
 
 func init() {
 	flag.StringVar(&parserType, "type", defaultParserType, "which type of test output to parse")
+	flag.StringVar(&parserConfig, "config", "", "the path to the configuration of the type of test output, for types that need to be configured")
 	flag.StringVar(&builderType, "suites", defaultBuilderType, "which test suite structure to use")
 	flag.StringVar(&rootSuites, "roots", "", "comma-delimited list of root suite names")
-	flag.StringVar(&testOutputFile, "f", defaultTestOutputFile, "the path to the file containing test output to consume")
+	flag.Var(&testOutputFiles, "f", "the path or glob pattern of the files containing test output to consume, can be repeated; defaults to stdin")
+	flag.BoolVar(&namespaceSuites, "namespace", false, "prefix the names of the test suites with the name of the file they are parsed from")
 	flag.StringVar(&outputFile, "output", defaultOutputFile, "the path to the jUnit XML output file to write")
+	flag.BoolVar(&nestSubtests, "subtests", false, "nest 'go test' subtests in test suites for their parent tests")
 	flag.BoolVar(&stream, "stream", defaultFilter, "print a streamed subset of the input as it is read")
+	flag.BoolVar(&incrementalOutput, "incremental", false, "write every test suite as soon as it is complete, only for flat test suites")
+	flag.Var(&redactions, "redact", "a regular expression matching test output to be replaced with [REDACTED], can be repeated")
+	flag.IntVar(&maxOutputBytes, "max-output", 0, "the maximum size in bytes of every output of a test case, larger output is truncated; 0 for no limit")
+	flag.Var(&includeTests, "include", "a regular expression matching the names of test cases to report, can be repeated")
+	flag.Var(&excludeTests, "exclude", "a regular expression matching the names of test cases not to report, can be repeated")
 }
 
 const (
 	junitReportUsageLong = `Consume test output to create jUnit XML files and summarize jUnit XML files.
 
-%[1]s consumes test output through Stdin and creates jUnit XML files. Currently, only the output of 'go test'
-and the output of 'oscmd' functions with $JUNIT_REPORT_OUTPUT set are supported. jUnit XML can be build with
-nested or flat test suites. Sub-trees of test suites can be selected when using the nested test-suites represen-
-tation to only build XML for some subset of the test output. This parser is greedy, so all output not directly
-related to a test suite is considered test case output.
+%[1]s consumes test output through Stdin and creates jUnit XML files. Currently, only the output of 'go test',
+the output of 'go test -json', the output of 'oscmd' functions with $JUNIT_REPORT_OUTPUT set, TAP (Test Anything
+Protocol) streams and output with begin and end markers described by regular expressions are supported. jUnit XML
+can be built with nested or flat test suites. Sub-trees of test suites can be selected when using the nested test-
+suites representation to only build XML for some subset of the test output. This parser is greedy, so all output
+not directly related to a test suite is considered test case output.
 `
 
 	junitReportUsage = `Usage:
-  %[1]s [--type=TEST-OUTPUT-TYPE] [--suites=SUITE-TYPE] [-f=FILE]
-  %[1]s [-f=FILE] summarize
+  %[1]s [--type=TEST-OUTPUT-TYPE] [--config=FILE] [--suites=SUITE-TYPE] [--subtests] [--incremental]
+      [-f=FILE ...] [--namespace] [--redact=PATTERN ...] [--max-output=BYTES] [--include=PATTERN ...] [--exclude=PATTERN ...]
+  %[1]s [-f=FILE] summarize [--format=SUMMARY-FORMAT]
+  %[1]s [-f=FILE] coverage [--format=REPORT-FORMAT] [--threshold=PACKAGE-PATTERN=MINIMUM ...]
+  %[1]s [-f=FILE] html [--title=TITLE] [--slowest=NUMBER]
+  %[1]s [-f=FILE] timings [--format=REPORT-FORMAT] [--slowest=NUMBER]
+  %[1]s [-f=FILE] validate [--format=REPORT-FORMAT]
+  %[1]s [-f=FILE] normalize
+  %[1]s diff [--format=REPORT-FORMAT] [--slowdown=FACTOR] [--min-slowdown=DURATION] BASELINE-FILE FILE
 `
 
 	junitReportExamples = `Examples:
@@ -72,6 +116,9 @@ related to a test suite is considered test case output.
   # Consume 'go test' output from a file to create a jUnit XML file
   %[1]s -f testoutput.txt > report.xml
 
+  # Consume 'go test' output split across the logs of several shards, keeping the test suites of every shard apart
+  %[1]s -f 'logs/shard-*.txt' --suites=nested --namespace > report.xml
+
   # Consume 'go test' output to create a specific jUnit XML file
   %[1]s --output report.xml
 
@@ -81,11 +128,53 @@ related to a test suite is considered test case output.
   # Consume 'go test' output to create a jUnit XML file with nested test suites rooted at 'github.com/maintainer'
   go test -v -cover ./... | junitreport --suites=nested --roots=github.com/maintainer > report.xml
 
+  # Consume 'go test -json' output to create a jUnit XML file with nested test suites
+  go test -json -cover ./... | %[1]s --type=gojson --suites=nested > report.xml
+
+  # Consume 'go test' output to create a jUnit XML file with a test suite for every test that has subtests
+  go test -v -cover ./... | %[1]s --suites=nested --subtests > report.xml
+
+  # Consume 'go test' output from a long-running job, writing every package to the jUnit XML file as it completes
+  go test -v -timeout=4h ./test/e2e/... | %[1]s --incremental --output report.xml
+
+  # Consume 'go test' output to create a jUnit XML file that can be published, without credentials or huge logs
+  go test -v ./... | %[1]s --redact='token=\S+' --redact='[a-z0-9.-]+\.internal\.example\.com' --max-output=65536 > report.xml
+
+  # Consume 'go test' output to create a jUnit XML file for the end-to-end tests only, without the serial tests
+  go test -v ./test/... | %[1]s --include='^TestE2E' --exclude='Serial' > report.xml
+
   # Describe failures and skipped tests in an existing jUnit XML file
   %[1]s summarize <report.xml
 
+  # Describe failures and skipped tests in an existing jUnit XML file in Markdown, e.g. for a pull request comment
+  %[1]s -f report.xml summarize --format=markdown > summary.md
+
+  # Report the coverage recorded in an existing jUnit XML file, failing if any package is below 60%% coverage
+  %[1]s -f report.xml coverage --threshold=github.com/maintainer/repository/...=60
+
+  # Render an existing jUnit XML file as a self-contained HTML page, e.g. to be stored as a CI artifact
+  %[1]s -f report.xml html --title="Unit tests" > report.html
+
+  # Find out which test cases and test suites take the most time in an existing jUnit XML file
+  %[1]s -f report.xml timings --slowest=10
+
+  # Check that a jUnit XML file is well-formed and that the counts of its test suites match their test cases
+  %[1]s -f report.xml validate
+
+  # Repair the counts and durations of the test suites in a jUnit XML file edited by hand
+  %[1]s -f edited-report.xml normalize > report.xml
+
+  # Compare a jUnit XML file with the one from the last successful run, failing if any tests newly failed
+  %[1]s diff last-successful-report.xml report.xml
+
   # Consume 'os::cmd' output from to create a jUnit XML file
   JUNIT_REPORT='true' hack/test-cmd.sh | junitreport --type=os::cmd > report.xml
+
+  # Consume the output of a test harness with its own markers, described by regular expressions in a configuration
+  hack/test-harness.sh | %[1]s --type=regex --config=harness-format.json > report.xml
+
+  # Consume TAP output to create a jUnit XML file with subtests as nested test suites
+  prove --verbose t/ | %[1]s --type=tap --suites=nested > report.xml
 `
 )
 
@@ -94,6 +183,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, junitReportUsageLong+"\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, junitReportUsage+"\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, junitReportExamples+"\n", os.Args[0])
+		printTypes()
 		fmt.Fprintln(os.Stderr, "Options:")
 		flag.PrintDefaults()
 		os.Exit(2)
@@ -106,27 +196,213 @@ func main() {
 		rootSuiteNames = strings.Split(rootSuites, ",")
 	}
 
-	var input io.Reader
-	if testOutputFile == defaultTestOutputFile {
-		input = os.Stdin
-	} else {
-		file, err := os.Open(testOutputFile)
+	inputs := []cmd.TestOutput{{Name: "stdin", Reader: os.Stdin}}
+	if len(testOutputFiles) > 0 {
+		paths, err := cmd.ExpandInputFiles(testOutputFiles)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading input file: %v\n", err)
+			os.Exit(1)
+		}
+		inputs = nil
+		for _, path := range paths {
+			file, err := os.Open(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading input file: %v\n", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			inputs = append(inputs, cmd.TestOutput{Name: path, Reader: file})
 		}
-		defer file.Close()
-		input = file
 	}
+	input := inputs[0].Reader
 
 	arguments := flag.Args()
+	// The commands consuming jUnit XML read a single document
+	if len(arguments) > 0 && len(inputs) > 1 {
+		fmt.Fprintf(os.Stderr, "Incorrect usage of %[1]s %[2]s, only one input file can be given, see '%[1]s --help' for more details.\n", os.Args[0], arguments[0])
+		os.Exit(1)
+	}
 	// If we are asked to summarize an XML file, that is all we do
-	if len(arguments) == 1 && arguments[0] == "summarize" {
-		summary, err := cmd.Summarize(input)
-		if err != nil {
+	if len(arguments) > 0 && arguments[0] == "summarize" {
+		summarizeFlags := flag.NewFlagSet("summarize", flag.ExitOnError)
+		summaryFormat := summarizeFlags.String("format", "text", "the format of the summary: text, json or markdown")
+		summarizeFlags.Parse(arguments[1:])
+		if summarizeFlags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "Incorrect usage of %[1]s summarize, see '%[1]s --help' for more details.\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		options := cmd.SummarizeOptions{
+			Input:  input,
+			Output: os.Stdout,
+		}
+		if err := options.Complete(*summaryFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if err := options.Run(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error summarizing jUnit XML file: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprint(os.Stdout, summary)
+		os.Exit(0)
+	}
+	// If we are asked to report the coverage in an XML file, that is all we do
+	if len(arguments) > 0 && arguments[0] == "coverage" {
+		coverageFlags := flag.NewFlagSet("coverage", flag.ExitOnError)
+		reportFormat := coverageFlags.String("format", "text", "the format of the coverage report: text or json")
+		var thresholds stringSlice
+		coverageFlags.Var(&thresholds, "threshold", "the minimum coverage of packages matching a pattern, as PATTERN=MINIMUM, can be repeated")
+		coverageFlags.Parse(arguments[1:])
+		if coverageFlags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "Incorrect usage of %[1]s coverage, see '%[1]s --help' for more details.\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		options := cmd.CoverageOptions{
+			Input:  input,
+			Output: os.Stdout,
+		}
+		if err := options.Complete(*reportFormat, thresholds); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if err := options.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reporting coverage: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	// If we are asked to render an XML file as HTML, that is all we do
+	if len(arguments) > 0 && arguments[0] == "html" {
+		htmlFlags := flag.NewFlagSet("html", flag.ExitOnError)
+		title := htmlFlags.String("title", "Test report", "the title of the HTML page")
+		numSlowest := htmlFlags.Int("slowest", 20, "the number of test cases and test suites listed in the tables of the slowest ones")
+		htmlFlags.Parse(arguments[1:])
+		if htmlFlags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "Incorrect usage of %[1]s html, see '%[1]s --help' for more details.\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		options := cmd.HTMLOptions{
+			Input:  input,
+			Output: os.Stdout,
+		}
+		if err := options.Complete(*title, *numSlowest); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if err := options.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering jUnit XML file as HTML: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	// If we are asked to analyze the durations in an XML file, that is all we do
+	if len(arguments) > 0 && arguments[0] == "timings" {
+		timingsFlags := flag.NewFlagSet("timings", flag.ExitOnError)
+		reportFormat := timingsFlags.String("format", "text", "the format of the timing report: text or json")
+		numSlowest := timingsFlags.Int("slowest", 10, "the number of test cases and test suites listed as the slowest ones")
+		timingsFlags.Parse(arguments[1:])
+		if timingsFlags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "Incorrect usage of %[1]s timings, see '%[1]s --help' for more details.\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		options := cmd.TimingsOptions{
+			Input:  input,
+			Output: os.Stdout,
+		}
+		if err := options.Complete(*reportFormat, *numSlowest); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if err := options.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error analyzing timings: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	// If we are asked to validate an XML file, that is all we do
+	if len(arguments) > 0 && arguments[0] == "validate" {
+		validateFlags := flag.NewFlagSet("validate", flag.ExitOnError)
+		reportFormat := validateFlags.String("format", "text", "the format of the violations: text or json")
+		validateFlags.Parse(arguments[1:])
+		if validateFlags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "Incorrect usage of %[1]s validate, see '%[1]s --help' for more details.\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		options := cmd.ValidateOptions{
+			Input:  input,
+			Output: os.Stdout,
+		}
+		if err := options.Complete(*reportFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if err := options.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error validating jUnit XML file: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	// If we are asked to normalize an XML file, that is all we do
+	if len(arguments) > 0 && arguments[0] == "normalize" {
+		normalizeFlags := flag.NewFlagSet("normalize", flag.ExitOnError)
+		normalizeFlags.Parse(arguments[1:])
+		if normalizeFlags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "Incorrect usage of %[1]s normalize, see '%[1]s --help' for more details.\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		options := cmd.NormalizeOptions{
+			Input:  input,
+			Output: os.Stdout,
+		}
+		if err := options.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error normalizing jUnit XML file: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	// If we are asked to compare two XML files, that is all we do
+	if len(arguments) > 0 && arguments[0] == "diff" {
+		diffFlags := flag.NewFlagSet("diff", flag.ExitOnError)
+		diffFormat := diffFlags.String("format", "text", "the format of the difference: text or json")
+		slowdownFactor := diffFlags.Float64("slowdown", 2, "the factor by which the duration of a test needs to grow to be reported as slower")
+		minSlowdown := diffFlags.String("min-slowdown", "1s", "the duration by which the duration of a test needs to grow to be reported as slower")
+		diffFlags.Parse(arguments[1:])
+		if diffFlags.NArg() != 2 {
+			fmt.Fprintf(os.Stderr, "Incorrect usage of %[1]s diff, see '%[1]s --help' for more details.\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		baseline, err := os.Open(diffFlags.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading baseline file: %v\n", err)
+			os.Exit(1)
+		}
+		defer baseline.Close()
+		current, err := os.Open(diffFlags.Arg(1))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input file: %v\n", err)
+			os.Exit(1)
+		}
+		defer current.Close()
+
+		options := cmd.DiffOptions{
+			Baseline: baseline,
+			Input:    current,
+			Output:   os.Stdout,
+		}
+		if err := options.Complete(*diffFormat, *slowdownFactor, *minSlowdown); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if err := options.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error comparing jUnit XML files: %v\n", err)
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 	if len(arguments) > 1 {
@@ -147,10 +423,24 @@ func main() {
 	}
 
 	// Otherwise, we get ready to parse and generate XML output.
+	var filter *cmd.OutputFilter
+	if len(redactions) > 0 || maxOutputBytes != 0 || len(includeTests) > 0 || len(excludeTests) > 0 {
+		var err error
+		filter, err = cmd.NewOutputFilter(redactions, includeTests, excludeTests, maxOutputBytes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	options := cmd.JUnitReportOptions{
-		Stream: stream,
-		Input:  input,
-		Output: output,
+		ParserConfig:    parserConfig,
+		NestSubtests:    nestSubtests,
+		Stream:          stream,
+		Incremental:     incrementalOutput,
+		Filter:          filter,
+		NamespaceSuites: namespaceSuites,
+		Inputs:          inputs,
+		Output:          output,
 	}
 
 	err := options.Complete(builderType, parserType, rootSuiteNames)
@@ -159,9 +449,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	if incrementalOutput {
+		// if we are interrupted, the suites written so far should still form a valid jUnit XML file
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			sig := <-signals
+			if err := options.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error closing output: %v\n", err)
+			}
+			fmt.Fprintf(os.Stderr, "Interrupted by %v, wrote test suites completed so far\n", sig)
+			os.Exit(1)
+		}()
+	}
+
 	err = options.Run()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error generating output: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// printTypes lists the registered test output types and test suite types with their descriptions
+func printTypes() {
+	writer := tabwriter.NewWriter(os.Stderr, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "Test output types (--type):")
+	for _, name := range parser.Names() {
+		fmt.Fprintf(writer, "  %s\t%s\n", name, parser.Describe(name))
+	}
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "Test suite types (--suites):")
+	for _, name := range builder.Names() {
+		fmt.Fprintf(writer, "  %s\t%s\n", name, builder.Describe(name))
+	}
+	fmt.Fprintln(writer)
+	writer.Flush()
+}
+
+// stringSlice is a flag that holds all of the values it is given
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
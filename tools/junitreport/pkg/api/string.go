@@ -28,6 +28,11 @@ func (t *TestCase) String() string {
 		message = t.FailureOutput.Message
 		output = t.FailureOutput.Output
 	}
+	if t.ErrorOutput != nil {
+		result = "errored"
+		message = t.ErrorOutput.Message
+		output = t.ErrorOutput.Output
+	}
 
 	return fmt.Sprintf("Test Case %q %s after %f seconds with message %q and output %q.", t.Name, result, t.Duration, message, output)
 }
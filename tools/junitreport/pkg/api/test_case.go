@@ -28,3 +28,109 @@ func (t *TestCase) MarkFailed(message, output string) {
 		Output:  output,
 	}
 }
+
+// MarkErrored marks the test as having encountered an error other than a failed assertion with the given
+// message and output
+func (t *TestCase) MarkErrored(message, output string) {
+	t.ErrorOutput = &ErrorOutput{
+		Message: message,
+		Output:  output,
+	}
+}
+
+// SetLocation records the source file and line at which the test is declared
+func (t *TestCase) SetLocation(file string, line int) {
+	t.File = file
+	t.Line = line
+}
+
+// AddProperty adds a property to the test case, deduplicating multiple additions of the same property
+// by overwriting the previous record to reflect the new values
+func (t *TestCase) AddProperty(name, value string) {
+	if t.Properties == nil {
+		t.Properties = &TestCaseProperties{}
+	}
+	for _, property := range t.Properties.Properties {
+		if property.Name == name {
+			property.Value = value
+			return
+		}
+	}
+
+	t.Properties.Properties = append(t.Properties.Properties, &TestCaseProperty{Name: name, Value: value})
+}
+
+// Result returns the result of the test case, considering tests that errored as failed
+func (t *TestCase) Result() TestResult {
+	switch {
+	case t.SkipMessage != nil:
+		return TestResultSkip
+	case t.FailureOutput != nil, t.ErrorOutput != nil:
+		return TestResultFail
+	default:
+		return TestResultPass
+	}
+}
+
+// MergeAttempts merges the attempts of a test that was run more than once, like with `go test -count`, into a
+// single test case following the conventions of the Maven Surefire plugin for rerun tests. A test that both passed
+// and failed is flaky: it is considered passed, and every failed attempt is recorded as a flaky failure. A test that
+// failed every time it was run is considered failed with the failure of its first attempt, and every later attempt
+// is recorded as a rerun failure. The duration of the test case is the sum of the durations of all attempts.
+func MergeAttempts(attempts []*TestCase) *TestCase {
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	var passed, failed []*TestCase
+	var duration float64
+	for _, attempt := range attempts {
+		switch attempt.Result() {
+		case TestResultPass:
+			passed = append(passed, attempt)
+		case TestResultFail:
+			failed = append(failed, attempt)
+		}
+		duration += attempt.Duration
+	}
+
+	var merged TestCase
+	switch {
+	case len(passed) > 0:
+		merged = *passed[0]
+		for _, attempt := range failed {
+			merged.FlakyFailures = append(merged.FlakyFailures, newRerunFailure(attempt))
+		}
+	case len(failed) > 0:
+		merged = *failed[0]
+		for _, attempt := range failed[1:] {
+			merged.RerunFailures = append(merged.RerunFailures, newRerunFailure(attempt))
+		}
+	default:
+		// the test was skipped every time it was run
+		merged = *attempts[0]
+	}
+
+	// we round to the millisecond on duration
+	merged.Duration = float64(int(duration*1000+0.5)) / 1000
+	return &merged
+}
+
+// newRerunFailure records the failure of an attempt of a test that was run more than once
+func newRerunFailure(attempt *TestCase) *RerunFailure {
+	failure := &RerunFailure{
+		SystemOut: attempt.SystemOut,
+		SystemErr: attempt.SystemErr,
+	}
+	switch {
+	case attempt.FailureOutput != nil:
+		failure.Message = attempt.FailureOutput.Message
+		failure.Type = attempt.FailureOutput.Type
+		failure.StackTrace = attempt.FailureOutput.Output
+	case attempt.ErrorOutput != nil:
+		failure.Message = attempt.ErrorOutput.Message
+		failure.Type = attempt.ErrorOutput.Type
+		failure.StackTrace = attempt.ErrorOutput.Output
+	}
+	return failure
+}
@@ -1,6 +1,10 @@
 package api
 
-import "time"
+import (
+	"math"
+	"sort"
+	"time"
+)
 
 // AddProperty adds a property to the test suite, deduplicating multiple additions of the same property
 // by overwriting the previous record to reflect the new values
@@ -17,6 +21,18 @@ func (t *TestSuite) AddProperty(name, value string) {
 
 // AddTestCase adds a test case to the test suite and updates test suite metrics as necessary
 func (t *TestSuite) AddTestCase(testCase *TestCase) {
+	if testCase.SkipMessage == nil && testCase.FailureOutput == nil && testCase.ErrorOutput == nil {
+		// we do not preserve output on tests that are not failures or skips
+		testCase.SystemOut = ""
+		testCase.SystemErr = ""
+	}
+	t.countTestCase(testCase)
+
+	t.TestCases = append(t.TestCases, testCase)
+}
+
+// countTestCase updates test suite metrics to encompass those of the test case
+func (t *TestSuite) countTestCase(testCase *TestCase) {
 	t.NumTests += 1
 
 	switch {
@@ -24,17 +40,68 @@ func (t *TestSuite) AddTestCase(testCase *TestCase) {
 		t.NumSkipped += 1
 	case testCase.FailureOutput != nil:
 		t.NumFailed += 1
-	default:
-		// we do not preserve output on tests that are not failures or skips
-		testCase.SystemOut = ""
-		testCase.SystemErr = ""
+	case testCase.ErrorOutput != nil:
+		t.NumErrored += 1
+	}
+	if len(testCase.FlakyFailures) > 0 {
+		t.NumFlaky += 1
 	}
 
 	t.Duration += testCase.Duration
 	// we round to the millisecond on duration
 	t.Duration = float64(int(t.Duration*1000)) / 1000
+}
 
-	t.TestCases = append(t.TestCases, testCase)
+// AddChild nests a test suite under the test suite and updates test suite metrics to encompass those of the child
+func (t *TestSuite) AddChild(child *TestSuite) {
+	t.NumTests += child.NumTests
+	t.NumSkipped += child.NumSkipped
+	t.NumFailed += child.NumFailed
+	t.NumErrored += child.NumErrored
+	t.NumFlaky += child.NumFlaky
+	t.Duration += child.Duration
+
+	t.Children = append(t.Children, child)
+}
+
+// Normalize recomputes the metrics of the test suite and of all suites nested under it from their test cases,
+// bottom-up, and sorts test cases and nested suites by name. The recorded duration of a suite is kept if it is
+// at least the sum of the durations of its test cases and nested suites, as it may include time spent outside of
+// any test case, like the time taken to set up a package.
+func (t *TestSuite) Normalize() {
+	recordedDuration := t.Duration
+	testCases, children := t.TestCases, t.Children
+	t.NumTests, t.NumSkipped, t.NumFailed, t.NumErrored, t.NumFlaky = 0, 0, 0, 0, 0
+	t.Duration = 0
+	t.TestCases, t.Children = nil, nil
+
+	sort.SliceStable(testCases, func(i, j int) bool {
+		return testCases[i].Name < testCases[j].Name
+	})
+	for _, testCase := range testCases {
+		t.countTestCase(testCase)
+		t.TestCases = append(t.TestCases, testCase)
+	}
+
+	sort.Stable(ByName(children))
+	for _, child := range children {
+		child.Normalize()
+		t.AddChild(child)
+	}
+
+	// we round to the millisecond on duration
+	t.Duration = math.Round(t.Duration*1000) / 1000
+	if recordedDuration > t.Duration {
+		t.Duration = recordedDuration
+	}
+}
+
+// Normalize recomputes the metrics of all test suites from their test cases and sorts them by name
+func (t *TestSuites) Normalize() {
+	sort.Stable(ByName(t.Suites))
+	for _, suite := range t.Suites {
+		suite.Normalize()
+	}
 }
 
 // SetDuration sets the duration of the test suite if this value is not calculated by aggregating the durations
@@ -32,11 +32,17 @@ type TestSuite struct {
 	// NumFailed records the number of failed tests in the suite
 	NumFailed uint `xml:"failures,attr"`
 
+	// NumErrored records the number of tests in the suite that encountered an error other than a failed assertion
+	NumErrored uint `xml:"errors,attr,omitempty"`
+
+	// NumFlaky records the number of tests in the suite that both passed and failed when they were run more than once
+	NumFlaky uint `xml:"flakes,attr,omitempty"`
+
 	// Duration is the time taken in seconds to run all tests in the suite
 	Duration float64 `xml:"time,attr"`
 
 	// Properties holds other properties of the test suite as a mapping of name to value
-	Properties []*TestSuiteProperty `xml:"properties,omitempty"`
+	Properties []*TestSuiteProperty `xml:"property,omitempty"`
 
 	// TestCases are the test cases contained in the test suite
 	TestCases []*TestCase `xml:"testcase"`
@@ -66,12 +72,34 @@ type TestCase struct {
 	// Duration is the time taken in seconds to run the test
 	Duration float64 `xml:"time,attr"`
 
+	// File is the source file declaring the test, if known
+	File string `xml:"file,attr,omitempty"`
+
+	// Line is the line in the source file at which the test is declared, if known
+	Line int `xml:"line,attr,omitempty"`
+
+	// Assertions records the number of assertions made by the test, if known
+	Assertions uint `xml:"assertions,attr,omitempty"`
+
+	// Properties holds other properties of the test case as a mapping of name to value
+	Properties *TestCaseProperties `xml:"properties,omitempty"`
+
 	// SkipMessage holds the reason why the test was skipped
 	SkipMessage *SkipMessage `xml:"skipped"`
 
 	// FailureOutput holds the output from a failing test
 	FailureOutput *FailureOutput `xml:"failure"`
 
+	// ErrorOutput holds the output from a test that encountered an error other than a failed assertion
+	ErrorOutput *ErrorOutput `xml:"error"`
+
+	// RerunFailures holds the output from the failed attempts of a test that failed every time it was run,
+	// other than the first attempt, which is recorded as the failure of the test
+	RerunFailures []*RerunFailure `xml:"rerunFailure,omitempty"`
+
+	// FlakyFailures holds the output from the failed attempts of a test that passed when it was run again
+	FlakyFailures []*RerunFailure `xml:"flakyFailure,omitempty"`
+
 	// SystemOut is output written to stdout during the execution of this test case
 	SystemOut string `xml:"system-out,omitempty"`
 
@@ -79,6 +107,21 @@ type TestCase struct {
 	SystemErr string `xml:"system-err,omitempty"`
 }
 
+// TestCaseProperties holds the properties of a test case
+type TestCaseProperties struct {
+	XMLName xml.Name `xml:"properties"`
+
+	Properties []*TestCaseProperty `xml:"property"`
+}
+
+// TestCaseProperty contains a mapping of a property name to a value
+type TestCaseProperty struct {
+	XMLName xml.Name `xml:"property"`
+
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
 // SkipMessage holds a message explaining why a test was skipped
 type SkipMessage struct {
 	XMLName xml.Name `xml:"skipped"`
@@ -94,10 +137,47 @@ type FailureOutput struct {
 	// Message holds the failure message from the test
 	Message string `xml:"message,attr"`
 
+	// Type is the type of the failure, e.g. the class of the assertion error that was raised
+	Type string `xml:"type,attr,omitempty"`
+
 	// Output holds verbose failure output from the test
 	Output string `xml:",chardata"`
 }
 
+// ErrorOutput holds the output from a test that encountered an error other than a failed assertion, like an
+// unexpected exception, as distinguished by Surefire and JUnit 5 consumers
+type ErrorOutput struct {
+	XMLName xml.Name `xml:"error"`
+
+	// Message holds the error message from the test
+	Message string `xml:"message,attr"`
+
+	// Type is the type of the error, e.g. the class of the exception that was raised
+	Type string `xml:"type,attr,omitempty"`
+
+	// Output holds verbose error output from the test
+	Output string `xml:",chardata"`
+}
+
+// RerunFailure holds the output from a failed attempt of a test that was run more than once, following the
+// conventions of the Maven Surefire plugin for rerun tests
+type RerunFailure struct {
+	// Message holds the failure message from the attempt
+	Message string `xml:"message,attr"`
+
+	// Type is the type of the failure
+	Type string `xml:"type,attr,omitempty"`
+
+	// StackTrace holds verbose failure output from the attempt
+	StackTrace string `xml:"stackTrace,omitempty"`
+
+	// SystemOut is output written to stdout during the attempt
+	SystemOut string `xml:"system-out,omitempty"`
+
+	// SystemErr is output written to stderr during the attempt
+	SystemErr string `xml:"system-err,omitempty"`
+}
+
 // TestResult is the result of a test case
 type TestResult string
 
@@ -5,6 +5,12 @@ import (
 	"github.com/openshift/origin/tools/junitreport/pkg/builder"
 )
 
+func init() {
+	builder.Register("flat", "a flat list of test suites", func(rootSuiteNames []string) builder.TestSuitesBuilder {
+		return NewTestSuitesBuilder()
+	})
+}
+
 // NewTestSuitesBuilder returns a new flat test suites builder. All test suites consumed
 // by this builder will be added to a flat list of suites - no suites will be children of other suites
 func NewTestSuitesBuilder() builder.TestSuitesBuilder {
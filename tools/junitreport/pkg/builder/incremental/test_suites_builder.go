@@ -0,0 +1,128 @@
+package incremental
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+// NewTestSuitesBuilder returns a new incremental test suites builder. All test suites consumed by this builder
+// are written to the output as a flat list of jUnit XML test suites as soon as they are added, so the output
+// holds every suite that has been completed even if the process is interrupted.
+func NewTestSuitesBuilder(output io.Writer) *IncrementalTestSuitesBuilder {
+	encoder := xml.NewEncoder(output)
+	encoder.Indent("\t", "\t") // suites are nested in the test suites element, indent with tabs
+
+	return &IncrementalTestSuitesBuilder{
+		output:  output,
+		encoder: encoder,
+	}
+}
+
+// IncrementalTestSuitesBuilder is a test suites builder that does not nest suites and writes every suite
+// as it is added instead of holding it in memory
+type IncrementalTestSuitesBuilder struct {
+	// lock guards the output, as the document may be closed from a signal handler while a suite is written
+	lock sync.Mutex
+
+	output  io.Writer
+	encoder *xml.Encoder
+
+	// started determines if the XML header and the opening test suites element have been written
+	started bool
+
+	// written determines if any test suite has been written
+	written bool
+
+	// closed determines if the closing test suites element has been written
+	closed bool
+
+	// err is the first error encountered while writing
+	err error
+}
+
+// AddSuite writes a test suite to the output. Once the suite has been written, its test cases and children
+// are released, as parsers may hold on to the suites they add and the suite should not stay in memory.
+func (b *IncrementalTestSuitesBuilder) AddSuite(suite *api.TestSuite) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed || b.err != nil {
+		return
+	}
+
+	b.start()
+	if b.err != nil {
+		return
+	}
+
+	if !b.written {
+		// the encoder only separates suites from each other, so the first one needs to start on a new line
+		if _, err := io.WriteString(b.output, "\n"); err != nil {
+			b.err = fmt.Errorf("error writing test suite %q: %v", suite.Name, err)
+			return
+		}
+		b.written = true
+	}
+	if err := b.encoder.Encode(suite); err != nil {
+		b.err = fmt.Errorf("error encoding test suite %q to XML: %v", suite.Name, err)
+		return
+	}
+	if err := b.encoder.Flush(); err != nil {
+		b.err = fmt.Errorf("error writing test suite %q: %v", suite.Name, err)
+		return
+	}
+
+	suite.TestCases = nil
+	suite.Children = nil
+}
+
+// start writes the XML header and the opening test suites element, if that has not been done yet
+func (b *IncrementalTestSuitesBuilder) start() {
+	if b.started {
+		return
+	}
+	b.started = true
+
+	if _, err := io.WriteString(b.output, xml.Header+"<testsuites>"); err != nil {
+		b.err = fmt.Errorf("error writing XML header to file: %v", err)
+	}
+}
+
+// Build closes the test suites document. As all suites have already been written, the returned test suites
+// collection is empty.
+func (b *IncrementalTestSuitesBuilder) Build() *api.TestSuites {
+	// errors are reported by Close, which callers are expected to call to learn if all suites were written
+	_ = b.Close()
+	return &api.TestSuites{}
+}
+
+// Close closes the test suites document so that the output is well-formed XML holding the suites written so
+// far, and returns the first error encountered while writing, if any. Suites added after the document has been
+// closed are ignored, so it is safe to call Close when the input ends early or the process is interrupted.
+func (b *IncrementalTestSuitesBuilder) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return b.err
+	}
+	b.closed = true
+
+	b.start()
+	if b.err != nil {
+		return b.err
+	}
+
+	closing := "</testsuites>\n"
+	if b.written {
+		closing = "\n" + closing
+	}
+	if _, err := io.WriteString(b.output, closing); err != nil {
+		b.err = fmt.Errorf("error writing closing element to file: %v", err)
+	}
+	return b.err
+}
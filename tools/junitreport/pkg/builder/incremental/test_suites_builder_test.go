@@ -0,0 +1,120 @@
+package incremental
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+func TestAddSuite(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		suitesToAdd []*api.TestSuite
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "single",
+			suitesToAdd: []*api.TestSuite{
+				{
+					Name:     "testSuite",
+					NumTests: 1,
+					TestCases: []*api.TestCase{
+						{
+							Name: "testCase",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "multiple",
+			suitesToAdd: []*api.TestSuite{
+				{
+					Name:     "testSuite",
+					NumTests: 1,
+					TestCases: []*api.TestCase{
+						{
+							Name: "testCase",
+						},
+					},
+				},
+				{
+					Name:      "testSuite2",
+					NumTests:  1,
+					NumFailed: 1,
+					Properties: []*api.TestSuiteProperty{
+						{
+							Name:  "coverage.statements.pct",
+							Value: "10.0",
+						},
+					},
+					TestCases: []*api.TestCase{
+						{
+							Name: "testCase2",
+							FailureOutput: &api.FailureOutput{
+								Output: "output",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		// the output should be identical to that of encoding the whole collection at once
+		var expected bytes.Buffer
+		expected.WriteString(xml.Header)
+		encoder := xml.NewEncoder(&expected)
+		encoder.Indent("", "\t")
+		if err := encoder.Encode(&api.TestSuites{Suites: testCase.suitesToAdd}); err != nil {
+			t.Fatalf("%s: unexpected error encoding test suites: %v", testCase.name, err)
+		}
+		expected.WriteString("\n")
+
+		var output bytes.Buffer
+		builder := NewTestSuitesBuilder(&output)
+		for _, suite := range testCase.suitesToAdd {
+			builder.AddSuite(suite)
+		}
+		if suites := builder.Build(); len(suites.Suites) != 0 {
+			t.Errorf("%s: expected no test suites to be held by the builder, got %d", testCase.name, len(suites.Suites))
+		}
+		if err := builder.Close(); err != nil {
+			t.Errorf("%s: unexpected error closing builder: %v", testCase.name, err)
+		}
+
+		if expected.String() != output.String() {
+			t.Errorf("%s: did not write the correct XML:\n\texpected:\n%s\n\tgot:\n%s", testCase.name, expected.String(), output.String())
+		}
+
+		for _, suite := range testCase.suitesToAdd {
+			if len(suite.TestCases) != 0 {
+				t.Errorf("%s: expected test cases of written suite %q to be released", testCase.name, suite.Name)
+			}
+		}
+	}
+}
+
+func TestAddSuiteAfterClose(t *testing.T) {
+	var output bytes.Buffer
+	builder := NewTestSuitesBuilder(&output)
+	builder.AddSuite(&api.TestSuite{Name: "testSuite"})
+	if err := builder.Close(); err != nil {
+		t.Fatalf("unexpected error closing builder: %v", err)
+	}
+	builder.AddSuite(&api.TestSuite{Name: "testSuite2"})
+	builder.Build()
+
+	var suites api.TestSuites
+	if err := xml.Unmarshal(output.Bytes(), &suites); err != nil {
+		t.Fatalf("did not write well-formed XML: %v\n%s", err, output.String())
+	}
+	if len(suites.Suites) != 1 || suites.Suites[0].Name != "testSuite" {
+		t.Errorf("expected only the test suite added before closing to be written, got:\n%s", output.String())
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"github.com/openshift/origin/tools/junitreport/pkg/builder"
 )
 
+func init() {
+	builder.Register("nested", "test suites nested by the '/'-delimited parts of their names, e.g. Go package paths", NewTestSuitesBuilder)
+}
+
 // NewTestSuitesBuilder returns a new nested test suites builder. All test suites consumed by
 // this builder will be added to a multitree of suites rooted at the suites with the given names.
 func NewTestSuitesBuilder(rootSuiteNames []string) builder.TestSuitesBuilder {
@@ -173,11 +177,7 @@ func updateMetrics(root *treeNode) {
 		updateMetrics(child)
 		// we should be building a tree, so updates on children are independent and we can bring
 		// in the updated data for this child right away
-		root.suite.NumTests += child.suite.NumTests
-		root.suite.NumSkipped += child.suite.NumSkipped
-		root.suite.NumFailed += child.suite.NumFailed
-		root.suite.Duration += child.suite.Duration
-		root.suite.Children = append(root.suite.Children, child.suite)
+		root.suite.AddChild(child.suite)
 	}
 
 	// we need to sort our children so that we can ensure reproducible output for testing
@@ -0,0 +1,68 @@
+package builder
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory returns a new test suites builder. Builders that build a hierarchy of test suites use the root suite
+// names, if any are given, as the roots of the hierarchy instead of the suite names without any delimiters.
+type Factory func(rootSuiteNames []string) TestSuitesBuilder
+
+// registration holds a builder factory and the description of the test suite structure it builds
+type registration struct {
+	description string
+	factory     Factory
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]registration{}
+)
+
+// Register makes a test suites builder available under the given name. Builder packages are expected to register
+// themselves when they are initialized, so that importing a package is enough to make its builder available, like
+// for `database/sql` drivers. If Register is called twice with the same name or if the factory is nil, it panics.
+func Register(name, description string, factory Factory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if factory == nil {
+		panic("builder: Register factory is nil")
+	}
+	if _, registered := registry[name]; registered {
+		panic(fmt.Sprintf("builder: Register called twice for builder %s", name))
+	}
+	registry[name] = registration{description: description, factory: factory}
+}
+
+// Lookup returns the factory of the test suites builder registered under the given name
+func Lookup(name string) (Factory, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	registered, ok := registry[name]
+	return registered.factory, ok
+}
+
+// Describe returns the description of the test suite structure built by the builder registered under the given name
+func Describe(name string) string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	return registry[name].description
+}
+
+// Names returns the sorted names of all registered test suites builders
+func Names() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	names := []string{}
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,48 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+type fakeTestSuitesBuilder struct {
+	rootSuiteNames []string
+}
+
+func (b *fakeTestSuitesBuilder) AddSuite(suite *api.TestSuite) {}
+
+func (b *fakeTestSuitesBuilder) Build() *api.TestSuites {
+	return &api.TestSuites{}
+}
+
+func TestRegister(t *testing.T) {
+	Register("fake", "fake test suites", func(rootSuiteNames []string) TestSuitesBuilder {
+		return &fakeTestSuitesBuilder{rootSuiteNames: rootSuiteNames}
+	})
+
+	factory, registered := Lookup("fake")
+	if !registered {
+		t.Fatalf("expected builder to be registered")
+	}
+	if builder := factory([]string{"root"}).(*fakeTestSuitesBuilder); !reflect.DeepEqual(builder.rootSuiteNames, []string{"root"}) {
+		t.Errorf("expected builder to be created with root suite names %v, got %v", []string{"root"}, builder.rootSuiteNames)
+	}
+	if _, registered := Lookup("missing"); registered {
+		t.Errorf("expected builder not to be registered")
+	}
+	if description := Describe("fake"); description != "fake test suites" {
+		t.Errorf("expected builder to be described as %q, got %q", "fake test suites", description)
+	}
+	if names := Names(); !reflect.DeepEqual(names, []string{"fake"}) {
+		t.Errorf("expected builder names to be %v, got %v", []string{"fake"}, names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected registering a builder twice to panic")
+		}
+	}()
+	Register("fake", "other fake test suites", func(rootSuiteNames []string) TestSuitesBuilder { return nil })
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser/gotest"
+)
+
+type CoverageOptions struct {
+	// Format is the format in which the coverage report is written
+	Format summaryFormat
+
+	// Thresholds are the minimum coverage required of the packages matching a pattern
+	Thresholds []CoverageThreshold
+
+	// Input is the reader for the jUnit XML holding coverage
+	Input io.Reader
+
+	// Output is the writer for the coverage report
+	Output io.Writer
+}
+
+// CoverageThreshold is the minimum coverage required of the packages matching a pattern
+type CoverageThreshold struct {
+	// Pattern matches the names of the packages the threshold applies to. Patterns are matched with path.Match,
+	// and a pattern ending in `/...` matches a package and all packages nested under it, like for `go test`
+	Pattern string `json:"pattern"`
+
+	// Minimum is the minimum percentage of statements that need to be covered
+	Minimum float64 `json:"minimum"`
+}
+
+func (o *CoverageOptions) Complete(format string, thresholds []string) error {
+	switch summaryFormat(format) {
+	case textSummaryFormat, jsonSummaryFormat:
+		o.Format = summaryFormat(format)
+	default:
+		return fmt.Errorf("unrecognized coverage report format: got %s, expected one of %v", format, []summaryFormat{textSummaryFormat, jsonSummaryFormat})
+	}
+
+	for _, threshold := range thresholds {
+		separator := strings.LastIndex(threshold, "=")
+		if separator < 1 {
+			return fmt.Errorf("invalid coverage threshold %q: expected PATTERN=MINIMUM", threshold)
+		}
+		pattern := threshold[:separator]
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid coverage threshold %q: %v", threshold, err)
+		}
+		minimum, err := strconv.ParseFloat(strings.TrimSuffix(threshold[separator+1:], "%"), 64)
+		if err != nil || minimum < 0 || minimum > 100 {
+			return fmt.Errorf("invalid coverage threshold %q: expected a minimum percentage between 0 and 100", threshold)
+		}
+		o.Thresholds = append(o.Thresholds, CoverageThreshold{Pattern: pattern, Minimum: minimum})
+	}
+
+	return nil
+}
+
+// Run writes the coverage report and returns an error if the coverage of any package is below a threshold
+func (o *CoverageOptions) Run() error {
+	testSuites, err := decodeTestSuites(o.Input)
+	if err != nil {
+		return err
+	}
+
+	report := NewCoverageReport(testSuites, o.Thresholds)
+
+	var output string
+	switch o.Format {
+	case jsonSummaryFormat:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding coverage report to JSON: %v", err)
+		}
+		output = string(data) + "\n"
+	default:
+		output = report.String()
+	}
+	if _, err := io.WriteString(o.Output, output); err != nil {
+		return err
+	}
+
+	if len(report.Violations) > 0 {
+		return fmt.Errorf("coverage of %d package(s) is below the minimum", len(report.Violations))
+	}
+	return nil
+}
+
+// CoverageReport describes the statement coverage recorded for the test suites in a jUnit XML file
+type CoverageReport struct {
+	// Coverage is the average coverage of all packages with coverage, each package weighing the same, as the
+	// number of statements in a package is not recorded
+	Coverage float64 `json:"coverage"`
+	// NumPackages is the number of packages with coverage
+	NumPackages int `json:"packages"`
+	// Suites holds the coverage of every suite that has coverage itself or holds suites that do, in depth-first order
+	Suites []SuiteCoverage `json:"suites"`
+	// Violations holds the packages whose coverage is below a threshold
+	Violations []CoverageViolation `json:"violations"`
+}
+
+// SuiteCoverage is the coverage of a test suite
+type SuiteCoverage struct {
+	// Name is the name of the suite
+	Name string `json:"name"`
+	// Coverage is the coverage of the package the suite represents or, if the suite does not represent a package,
+	// the average coverage of the packages nested under it
+	Coverage float64 `json:"coverage"`
+	// NumPackages is the number of packages with coverage the suite accounts for, including itself
+	NumPackages int `json:"packages"`
+	// Measured determines if the coverage was recorded for the suite itself rather than aggregated from the
+	// suites nested under it
+	Measured bool `json:"measured"`
+}
+
+// CoverageViolation is a package whose coverage is below a threshold
+type CoverageViolation struct {
+	// Name is the name of the package
+	Name string `json:"name"`
+	// Coverage is the coverage of the package
+	Coverage float64 `json:"coverage"`
+	// Threshold is the threshold that is violated
+	Threshold CoverageThreshold `json:"threshold"`
+}
+
+// NewCoverageReport collects the coverage of all test suites and checks the coverage of packages against thresholds
+func NewCoverageReport(testSuites *api.TestSuites, thresholds []CoverageThreshold) *CoverageReport {
+	report := &CoverageReport{
+		Suites:     []SuiteCoverage{},
+		Violations: []CoverageViolation{},
+	}
+
+	var total float64
+	for _, suite := range testSuites.Suites {
+		sum, count := collectCoverage(suite, thresholds, report)
+		total += sum
+		report.NumPackages += count
+	}
+	if report.NumPackages > 0 {
+		report.Coverage = roundPercentage(total / float64(report.NumPackages))
+	}
+	return report
+}
+
+// collectCoverage records the coverage of a suite and the suites nested under it in the report, returning the sum
+// of the coverage of the packages in the suite and the number of those packages
+func collectCoverage(suite *api.TestSuite, thresholds []CoverageThreshold, report *CoverageReport) (float64, int) {
+	// the suite is recorded before the suites nested under it, once its aggregate coverage is known
+	index := len(report.Suites)
+	report.Suites = append(report.Suites, SuiteCoverage{Name: suite.Name})
+
+	var sum float64
+	var count int
+	coverage, measured := suiteCoverage(suite)
+	if measured {
+		sum += coverage
+		count++
+		for _, threshold := range thresholds {
+			if matchesPackage(threshold.Pattern, suite.Name) && coverage < threshold.Minimum {
+				report.Violations = append(report.Violations, CoverageViolation{Name: suite.Name, Coverage: coverage, Threshold: threshold})
+			}
+		}
+	}
+
+	for _, child := range suite.Children {
+		childSum, childCount := collectCoverage(child, thresholds, report)
+		sum += childSum
+		count += childCount
+	}
+
+	if count == 0 {
+		// neither the suite nor any suite nested under it has coverage
+		report.Suites = report.Suites[:index]
+		return 0, 0
+	}
+	report.Suites[index].Coverage = coverage
+	if !measured || count > 1 {
+		report.Suites[index].Coverage = roundPercentage(sum / float64(count))
+	}
+	report.Suites[index].NumPackages = count
+	report.Suites[index].Measured = measured
+	return sum, count
+}
+
+// suiteCoverage returns the coverage recorded for the suite itself, if any
+func suiteCoverage(suite *api.TestSuite) (float64, bool) {
+	for _, property := range suite.Properties {
+		if property.Name != gotest.CoveragePropertyName {
+			continue
+		}
+		coverage, err := strconv.ParseFloat(property.Value, 64)
+		return coverage, err == nil
+	}
+	return 0, false
+}
+
+// matchesPackage determines if the name of a package matches a threshold pattern
+func matchesPackage(pattern, name string) bool {
+	if pattern == "..." {
+		return true
+	}
+	if strings.HasSuffix(pattern, "/...") {
+		prefix := strings.TrimSuffix(pattern, "/...")
+		if matched, _ := path.Match(prefix, name); matched {
+			return true
+		}
+		// the prefix may itself be a pattern, so it is matched against every parent of the package
+		for parent := name; strings.Contains(parent, "/"); {
+			parent = parent[:strings.LastIndex(parent, "/")]
+			if matched, _ := path.Match(prefix, parent); matched {
+				return true
+			}
+		}
+		return false
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// roundPercentage rounds a percentage to two decimal places
+func roundPercentage(percentage float64) float64 {
+	return float64(int(percentage*100+0.5)) / 100
+}
+
+// String formats the coverage report as a table of suites followed by the packages violating thresholds
+func (r *CoverageReport) String() string {
+	var report bytes.Buffer
+	if r.NumPackages == 0 {
+		report.WriteString("No coverage was recorded.\n")
+		return report.String()
+	}
+
+	report.WriteString(fmt.Sprintf("Average coverage of %d packages: %.1f%% of statements\n\n", r.NumPackages, r.Coverage))
+
+	writer := tabwriter.NewWriter(&report, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "SUITE\tCOVERAGE\tPACKAGES")
+	for _, suite := range r.Suites {
+		coverage := fmt.Sprintf("%.1f%%", suite.Coverage)
+		if !suite.Measured || suite.NumPackages > 1 {
+			coverage += " (average)"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\n", suite.Name, coverage, suite.NumPackages)
+	}
+	writer.Flush()
+
+	for _, violation := range r.Violations {
+		report.WriteString(fmt.Sprintf("\nCoverage of package %q is %.1f%%, below the minimum of %.1f%% for %q", violation.Name, violation.Coverage, violation.Threshold.Minimum, violation.Threshold.Pattern))
+	}
+	if len(r.Violations) > 0 {
+		report.WriteString("\n")
+	}
+	return report.String()
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+func newCoveredSuite(name, coverage string, children ...*api.TestSuite) *api.TestSuite {
+	suite := &api.TestSuite{Name: name, Children: children}
+	if len(coverage) > 0 {
+		suite.AddProperty("coverage.statements.pct", coverage)
+	}
+	return suite
+}
+
+func TestNewCoverageReport(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		suites         []*api.TestSuite
+		thresholds     []CoverageThreshold
+		expectedReport *CoverageReport
+	}{
+		{
+			name:   "no coverage",
+			suites: []*api.TestSuite{newCoveredSuite("package/name", "")},
+			expectedReport: &CoverageReport{
+				Suites:     []SuiteCoverage{},
+				Violations: []CoverageViolation{},
+			},
+		},
+		{
+			name: "flat",
+			suites: []*api.TestSuite{
+				newCoveredSuite("package/one", "10.0"),
+				newCoveredSuite("package/two", "20.5"),
+				newCoveredSuite("package/three", ""),
+			},
+			thresholds: []CoverageThreshold{{Pattern: "package/*", Minimum: 15}},
+			expectedReport: &CoverageReport{
+				Coverage:    15.25,
+				NumPackages: 2,
+				Suites: []SuiteCoverage{
+					{Name: "package/one", Coverage: 10, NumPackages: 1, Measured: true},
+					{Name: "package/two", Coverage: 20.5, NumPackages: 1, Measured: true},
+				},
+				Violations: []CoverageViolation{
+					{Name: "package/one", Coverage: 10, Threshold: CoverageThreshold{Pattern: "package/*", Minimum: 15}},
+				},
+			},
+		},
+		{
+			name: "nested",
+			suites: []*api.TestSuite{
+				newCoveredSuite("package", "",
+					newCoveredSuite("package/one", "10.0",
+						newCoveredSuite("package/one/sub", "40.0"),
+					),
+					newCoveredSuite("package/two", "",
+						newCoveredSuite("package/two/sub", "70.0"),
+						newCoveredSuite("package/two/other", ""),
+					),
+				),
+			},
+			thresholds: []CoverageThreshold{{Pattern: "package/two/...", Minimum: 80}, {Pattern: "package/one", Minimum: 5}},
+			expectedReport: &CoverageReport{
+				Coverage:    40,
+				NumPackages: 3,
+				Suites: []SuiteCoverage{
+					{Name: "package", Coverage: 40, NumPackages: 3},
+					{Name: "package/one", Coverage: 25, NumPackages: 2, Measured: true},
+					{Name: "package/one/sub", Coverage: 40, NumPackages: 1, Measured: true},
+					{Name: "package/two", Coverage: 70, NumPackages: 1},
+					{Name: "package/two/sub", Coverage: 70, NumPackages: 1, Measured: true},
+				},
+				Violations: []CoverageViolation{
+					{Name: "package/two/sub", Coverage: 70, Threshold: CoverageThreshold{Pattern: "package/two/...", Minimum: 80}},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			report := NewCoverageReport(&api.TestSuites{Suites: testCase.suites}, testCase.thresholds)
+			if !reflect.DeepEqual(report, testCase.expectedReport) {
+				t.Errorf("did not produce the correct coverage report:\n%s", diff.ObjectReflectDiff(testCase.expectedReport, report))
+			}
+		})
+	}
+}
+
+func TestMatchesPackage(t *testing.T) {
+	var testCases = []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "package/name", name: "package/name", expected: true},
+		{pattern: "package/*", name: "package/name", expected: true},
+		{pattern: "package/*", name: "package/name/sub", expected: false},
+		{pattern: "package/...", name: "package", expected: true},
+		{pattern: "package/...", name: "package/name/sub", expected: true},
+		{pattern: "package/...", name: "packages/name", expected: false},
+		{pattern: "*/name/...", name: "package/name/sub", expected: true},
+		{pattern: "...", name: "package/name", expected: true},
+	}
+
+	for _, testCase := range testCases {
+		if actual := matchesPackage(testCase.pattern, testCase.name); actual != testCase.expected {
+			t.Errorf("expected pattern %q matching %q to be %v, got %v", testCase.pattern, testCase.name, testCase.expected, actual)
+		}
+	}
+}
+
+func TestCoverageOptionsComplete(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		thresholds         []string
+		expectedThresholds []CoverageThreshold
+		expectedError      bool
+	}{
+		{
+			name:               "percentages",
+			thresholds:         []string{"package/...=60", "package/name=75.5%"},
+			expectedThresholds: []CoverageThreshold{{Pattern: "package/...", Minimum: 60}, {Pattern: "package/name", Minimum: 75.5}},
+		},
+		{
+			name:          "missing minimum",
+			thresholds:    []string{"package/..."},
+			expectedError: true,
+		},
+		{
+			name:          "out of range",
+			thresholds:    []string{"package/...=101"},
+			expectedError: true,
+		},
+		{
+			name:          "malformed pattern",
+			thresholds:    []string{"package/[=10"},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options := CoverageOptions{}
+			err := options.Complete("text", testCase.thresholds)
+			if (err != nil) != testCase.expectedError {
+				t.Fatalf("expected error to be %v, got %v", testCase.expectedError, err)
+			}
+			if !testCase.expectedError && !reflect.DeepEqual(options.Thresholds, testCase.expectedThresholds) {
+				t.Errorf("did not parse thresholds correctly:\n%s", diff.ObjectReflectDiff(testCase.expectedThresholds, options.Thresholds))
+			}
+		})
+	}
+}
+
+func TestCoverageOptionsRunSingleSuite(t *testing.T) {
+	var output strings.Builder
+	options := CoverageOptions{
+		Input: strings.NewReader(`<testsuite name="package/name" tests="1" skipped="0" failures="0" time="0.1">
+	<property name="coverage.statements.pct" value="50.0"></property>
+	<testcase name="TestOne" time="0.1"></testcase>
+</testsuite>`),
+		Output: &output,
+	}
+	if err := options.Complete("json", []string{"package/...=60"}); err != nil {
+		t.Fatalf("unexpected error completing options: %v", err)
+	}
+	if err := options.Run(); err == nil {
+		t.Errorf("expected an error for coverage below the minimum")
+	}
+
+	var report CoverageReport
+	if err := json.Unmarshal([]byte(output.String()), &report); err != nil {
+		t.Fatalf("unexpected error decoding coverage report: %v", err)
+	}
+	if report.NumPackages != 1 || report.Coverage != 50 || len(report.Violations) != 1 {
+		t.Errorf("did not report the coverage of the single suite: %s", output.String())
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+// decodeTestSuites reads jUnit XML holding either a collection of test suites or a single test suite, as written
+// by some tools
+func decodeTestSuites(input io.Reader) (*api.TestSuites, error) {
+	decoder := xml.NewDecoder(input)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no test suites found")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch element.Name.Local {
+		case "testsuites":
+			testSuites := &api.TestSuites{}
+			if err := decoder.DecodeElement(testSuites, &element); err != nil {
+				return nil, err
+			}
+			return testSuites, nil
+		case "testsuite":
+			testSuite := &api.TestSuite{}
+			if err := decoder.DecodeElement(testSuite, &element); err != nil {
+				return nil, err
+			}
+			return &api.TestSuites{Suites: []*api.TestSuite{testSuite}}, nil
+		default:
+			return nil, fmt.Errorf("unexpected root element %q, expected %q or %q", element.Name.Local, "testsuites", "testsuite")
+		}
+	}
+}
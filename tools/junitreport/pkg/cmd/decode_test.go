@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTestSuites(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		input         string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "test suites root",
+			input:    `<?xml version="1.0"?><testsuites><testsuite name="a" tests="0" skipped="0" failures="0" time="0"></testsuite><testsuite name="b" tests="0" skipped="0" failures="0" time="0"></testsuite></testsuites>`,
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "single test suite root",
+			input:    `<?xml version="1.0"?><testsuite name="package" tests="1" skipped="0" failures="0" time="0.1"><testcase name="TestPass" time="0.1"></testcase></testsuite>`,
+			expected: []string{"package"},
+		},
+		{
+			name:          "other root",
+			input:         `<report></report>`,
+			expectedError: `unexpected root element "report", expected "testsuites" or "testsuite"`,
+		},
+		{
+			name:          "empty",
+			input:         ``,
+			expectedError: "no test suites found",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testSuites, err := decodeTestSuites(strings.NewReader(testCase.input))
+			switch {
+			case len(testCase.expectedError) > 0:
+				if err == nil || err.Error() != testCase.expectedError {
+					t.Errorf("expected error %q, got %v", testCase.expectedError, err)
+				}
+			case err != nil:
+				t.Errorf("unexpected error decoding test suites: %v", err)
+			default:
+				var names []string
+				for _, suite := range testSuites.Suites {
+					names = append(names, suite.Name)
+				}
+				if !reflect.DeepEqual(names, testCase.expected) {
+					t.Errorf("did not decode the correct test suites: expected %v, got %v", testCase.expected, names)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+type DiffOptions struct {
+	// Format is the format in which the difference is written
+	Format summaryFormat
+
+	// SlowdownFactor is the factor by which the duration of a test needs to grow to be considered a regression
+	SlowdownFactor float64
+
+	// MinSlowdown is the time in seconds by which the duration of a test needs to grow to be considered a
+	// regression, so that fast tests are not reported for insignificant variations in their duration
+	MinSlowdown float64
+
+	// Baseline is the reader for the jUnit XML to compare against
+	Baseline io.Reader
+
+	// Input is the reader for the jUnit XML to be compared with the baseline
+	Input io.Reader
+
+	// Output is the writer for the difference
+	Output io.Writer
+}
+
+func (o *DiffOptions) Complete(format string, slowdownFactor float64, minSlowdown string) error {
+	switch summaryFormat(format) {
+	case textSummaryFormat, jsonSummaryFormat:
+		o.Format = summaryFormat(format)
+	default:
+		return fmt.Errorf("unrecognized difference format: got %s, expected one of %v", format, []summaryFormat{textSummaryFormat, jsonSummaryFormat})
+	}
+
+	if slowdownFactor < 1 {
+		return fmt.Errorf("invalid slowdown factor %v: expected a factor of at least 1", slowdownFactor)
+	}
+	o.SlowdownFactor = slowdownFactor
+
+	duration, err := time.ParseDuration(minSlowdown)
+	if err != nil {
+		return fmt.Errorf("invalid minimum slowdown %q: %v", minSlowdown, err)
+	}
+	o.MinSlowdown = duration.Seconds()
+
+	return nil
+}
+
+// Run writes the difference between the baseline and the input and returns an error if any tests newly failed
+func (o *DiffOptions) Run() error {
+	baseline, err := decodeTestSuites(o.Baseline)
+	if err != nil {
+		return fmt.Errorf("error decoding baseline: %v", err)
+	}
+	current, err := decodeTestSuites(o.Input)
+	if err != nil {
+		return err
+	}
+
+	difference := NewDiff(baseline, current, o.SlowdownFactor, o.MinSlowdown)
+
+	var output string
+	switch o.Format {
+	case jsonSummaryFormat:
+		data, err := json.MarshalIndent(difference, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding difference to JSON: %v", err)
+		}
+		output = string(data) + "\n"
+	default:
+		output = difference.String()
+	}
+	if _, err := io.WriteString(o.Output, output); err != nil {
+		return err
+	}
+
+	if len(difference.NewFailures) > 0 {
+		return fmt.Errorf("%d test(s) newly failed", len(difference.NewFailures))
+	}
+	return nil
+}
+
+// Diff describes how the tests in a jUnit XML file changed compared with a baseline
+type Diff struct {
+	// NewFailures holds the tests that failed but did not fail in the baseline, including tests that were added
+	NewFailures []TestCaseDiff `json:"newFailures"`
+	// Fixed holds the tests that passed but failed in the baseline
+	Fixed []TestCaseDiff `json:"fixed"`
+	// Added holds the tests that are not in the baseline
+	Added []TestCaseDiff `json:"added"`
+	// Removed holds the tests that are only in the baseline
+	Removed []TestCaseDiff `json:"removed"`
+	// Slower holds the tests whose duration grew significantly compared with the baseline
+	Slower []TestCaseDiff `json:"slower"`
+}
+
+// TestCaseDiff describes how a test case changed compared with a baseline
+type TestCaseDiff struct {
+	// Suite is the name of the suite holding the test case
+	Suite string `json:"suite"`
+	// Name is the name of the test case
+	Name string `json:"name"`
+	// BaselineResult is the result of the test case in the baseline, if it is in the baseline
+	BaselineResult api.TestResult `json:"baselineResult,omitempty"`
+	// Result is the result of the test case, if it was not removed
+	Result api.TestResult `json:"result,omitempty"`
+	// BaselineDuration is the time taken in seconds to run the test case in the baseline
+	BaselineDuration float64 `json:"baselineDuration"`
+	// Duration is the time taken in seconds to run the test case
+	Duration float64 `json:"duration"`
+}
+
+// testCaseID identifies a test case across jUnit XML files
+type testCaseID struct {
+	suite string
+	name  string
+}
+
+// testCaseRecord holds the outcome of a test case, which may have been run more than once
+type testCaseRecord struct {
+	result   api.TestResult
+	duration float64
+}
+
+// NewDiff compares the test cases in the test suites with the baseline. A test that ran more than once is considered
+// failed if any of its runs failed. A test is considered significantly slower if its duration grew by at least the
+// slowdown factor and by at least the minimum slowdown in seconds.
+func NewDiff(baseline, current *api.TestSuites, slowdownFactor, minSlowdown float64) *Diff {
+	difference := &Diff{
+		NewFailures: []TestCaseDiff{},
+		Fixed:       []TestCaseDiff{},
+		Added:       []TestCaseDiff{},
+		Removed:     []TestCaseDiff{},
+		Slower:      []TestCaseDiff{},
+	}
+
+	baselineIDs, baselineRecords := collectTestCaseRecords(baseline)
+	currentIDs, currentRecords := collectTestCaseRecords(current)
+
+	for _, id := range currentIDs {
+		record := currentRecords[id]
+		testCase := TestCaseDiff{
+			Suite:    id.suite,
+			Name:     id.name,
+			Result:   record.result,
+			Duration: record.duration,
+		}
+
+		baselineRecord, existed := baselineRecords[id]
+		if !existed {
+			difference.Added = append(difference.Added, testCase)
+			if record.result == api.TestResultFail {
+				difference.NewFailures = append(difference.NewFailures, testCase)
+			}
+			continue
+		}
+		testCase.BaselineResult = baselineRecord.result
+		testCase.BaselineDuration = baselineRecord.duration
+
+		switch {
+		case record.result == api.TestResultFail && baselineRecord.result != api.TestResultFail:
+			difference.NewFailures = append(difference.NewFailures, testCase)
+		case record.result == api.TestResultPass && baselineRecord.result == api.TestResultFail:
+			difference.Fixed = append(difference.Fixed, testCase)
+		}
+
+		// skipped tests do not run long enough for their duration to be meaningful
+		if record.result != api.TestResultSkip && baselineRecord.result != api.TestResultSkip &&
+			record.duration >= baselineRecord.duration*slowdownFactor && record.duration-baselineRecord.duration >= minSlowdown {
+			difference.Slower = append(difference.Slower, testCase)
+		}
+	}
+
+	for _, id := range baselineIDs {
+		if _, exists := currentRecords[id]; exists {
+			continue
+		}
+		baselineRecord := baselineRecords[id]
+		difference.Removed = append(difference.Removed, TestCaseDiff{
+			Suite:            id.suite,
+			Name:             id.name,
+			BaselineResult:   baselineRecord.result,
+			BaselineDuration: baselineRecord.duration,
+		})
+	}
+
+	return difference
+}
+
+// collectTestCaseRecords collects the outcome of every test case in the test suites, returning the test cases in
+// the order they first appear in
+func collectTestCaseRecords(testSuites *api.TestSuites) ([]testCaseID, map[testCaseID]*testCaseRecord) {
+	var ids []testCaseID
+	records := map[testCaseID]*testCaseRecord{}
+
+	var collect func(suite *api.TestSuite)
+	collect = func(suite *api.TestSuite) {
+		for _, testCase := range suite.TestCases {
+			id := testCaseID{suite: suite.Name, name: testCase.Name}
+			result := testCase.Result()
+			record, seen := records[id]
+			if !seen {
+				ids = append(ids, id)
+				records[id] = &testCaseRecord{result: result, duration: testCase.Duration}
+				continue
+			}
+			if result == api.TestResultFail || record.result == api.TestResultSkip {
+				record.result = result
+			}
+			if testCase.Duration > record.duration {
+				record.duration = testCase.Duration
+			}
+		}
+		for _, child := range suite.Children {
+			collect(child)
+		}
+	}
+	for _, suite := range testSuites.Suites {
+		collect(suite)
+	}
+
+	return ids, records
+}
+
+// String formats the difference as a count of the changes followed by a description of every changed test case
+func (d *Diff) String() string {
+	var difference bytes.Buffer
+	difference.WriteString(fmt.Sprintf("Compared with the baseline, %d tests newly failed, %d were fixed, %d were added, %d were removed and %d became significantly slower.\n", len(d.NewFailures), len(d.Fixed), len(d.Added), len(d.Removed), len(d.Slower)))
+
+	if len(d.NewFailures) > 0 {
+		difference.WriteString("\n")
+	}
+	for _, testCase := range d.NewFailures {
+		previously := fmt.Sprintf("%s in the baseline", describeResult(testCase.BaselineResult))
+		if len(testCase.BaselineResult) == 0 {
+			previously = "was not in the baseline"
+		}
+		difference.WriteString(fmt.Sprintf("In suite %q, test case %q failed, but %s.\n", testCase.Suite, testCase.Name, previously))
+	}
+
+	if len(d.Fixed) > 0 {
+		difference.WriteString("\n")
+	}
+	for _, testCase := range d.Fixed {
+		difference.WriteString(fmt.Sprintf("In suite %q, test case %q passed, but failed in the baseline.\n", testCase.Suite, testCase.Name))
+	}
+
+	if len(d.Added) > 0 {
+		difference.WriteString("\n")
+	}
+	for _, testCase := range d.Added {
+		difference.WriteString(fmt.Sprintf("In suite %q, test case %q was added and %s.\n", testCase.Suite, testCase.Name, describeResult(testCase.Result)))
+	}
+
+	if len(d.Removed) > 0 {
+		difference.WriteString("\n")
+	}
+	for _, testCase := range d.Removed {
+		difference.WriteString(fmt.Sprintf("In suite %q, test case %q was removed.\n", testCase.Suite, testCase.Name))
+	}
+
+	if len(d.Slower) > 0 {
+		difference.WriteString("\n")
+	}
+	for _, testCase := range d.Slower {
+		difference.WriteString(fmt.Sprintf("In suite %q, test case %q took %.3fs, up from %.3fs in the baseline.\n", testCase.Suite, testCase.Name, testCase.Duration, testCase.BaselineDuration))
+	}
+
+	return difference.String()
+}
+
+// describeResult describes a test result in the past tense
+func describeResult(result api.TestResult) string {
+	switch result {
+	case api.TestResultFail:
+		return "failed"
+	case api.TestResultSkip:
+		return "was skipped"
+	default:
+		return "passed"
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+const diffBaselineXML = `<testsuites>
+	<testsuite name="package" tests="5" skipped="1" failures="1" time="3.3">
+		<testcase name="TestStillPasses" time="0.1"></testcase>
+		<testcase name="TestNewlyFails" time="0.1"></testcase>
+		<testcase name="TestFixed" time="0.1"><failure message="boom"></failure></testcase>
+		<testcase name="TestRemoved" time="1"></testcase>
+		<testcase name="TestSkipped" time="0"><skipped message="not today"></skipped></testcase>
+		<testsuite name="package/child" tests="2" skipped="0" failures="0" time="2">
+			<testcase name="TestSlower" time="1"></testcase>
+			<testcase name="TestSlightlySlower" time="1"></testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>`
+
+const diffCurrentXML = `<testsuites>
+	<testsuite name="package" tests="6" skipped="0" failures="3" time="3.5">
+		<testcase name="TestStillPasses" time="0.1"></testcase>
+		<testcase name="TestNewlyFails" time="0.1"><failure message="boom"></failure></testcase>
+		<testcase name="TestFixed" time="0.1"></testcase>
+		<testcase name="TestSkipped" time="0.1"><error message="panic"></error></testcase>
+		<testcase name="TestAdded" time="0.1"></testcase>
+		<testcase name="TestAddedFailing" time="0.1"><failure message="boom"></failure></testcase>
+		<testsuite name="package/child" tests="2" skipped="0" failures="0" time="3">
+			<testcase name="TestSlower" time="2.5"></testcase>
+			<testcase name="TestSlightlySlower" time="1.5"></testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>`
+
+func TestDiffOptionsRun(t *testing.T) {
+	var output strings.Builder
+	options := DiffOptions{
+		Baseline: strings.NewReader(diffBaselineXML),
+		Input:    strings.NewReader(diffCurrentXML),
+		Output:   &output,
+	}
+	if err := options.Complete("text", 2, "1s"); err != nil {
+		t.Fatalf("unexpected error completing options: %v", err)
+	}
+	err := options.Run()
+	if err == nil || err.Error() != "3 test(s) newly failed" {
+		t.Errorf("expected an error for the tests that newly failed, got %v", err)
+	}
+
+	expected := `Compared with the baseline, 3 tests newly failed, 1 were fixed, 2 were added, 1 were removed and 1 became significantly slower.
+
+In suite "package", test case "TestNewlyFails" failed, but passed in the baseline.
+In suite "package", test case "TestSkipped" failed, but was skipped in the baseline.
+In suite "package", test case "TestAddedFailing" failed, but was not in the baseline.
+
+In suite "package", test case "TestFixed" passed, but failed in the baseline.
+
+In suite "package", test case "TestAdded" was added and passed.
+In suite "package", test case "TestAddedFailing" was added and failed.
+
+In suite "package", test case "TestRemoved" was removed.
+
+In suite "package/child", test case "TestSlower" took 2.500s, up from 1.000s in the baseline.
+`
+	if output.String() != expected {
+		t.Errorf("did not produce the correct difference:\n%s", diff.ObjectReflectDiff(expected, output.String()))
+	}
+}
+
+func TestDiffOptionsRunSingleSuites(t *testing.T) {
+	var output strings.Builder
+	options := DiffOptions{
+		Baseline: strings.NewReader(`<testsuite name="package" tests="1" skipped="0" failures="0" time="0.1">
+	<testcase name="TestOne" time="0.1"></testcase>
+</testsuite>`),
+		Input: strings.NewReader(`<testsuite name="package" tests="1" skipped="0" failures="1" time="0.1">
+	<testcase name="TestOne" time="0.1"><failure message="boom"></failure></testcase>
+</testsuite>`),
+		Output: &output,
+	}
+	if err := options.Complete("text", 2, "1s"); err != nil {
+		t.Fatalf("unexpected error completing options: %v", err)
+	}
+	err := options.Run()
+	if err == nil || err.Error() != "1 test(s) newly failed" {
+		t.Errorf("expected an error for the test that newly failed, got %v", err)
+	}
+	expected := `In suite "package", test case "TestOne" failed, but passed in the baseline.`
+	if !strings.Contains(output.String(), expected) {
+		t.Errorf("expected the difference to contain %q, got:\n%s", expected, output.String())
+	}
+}
+
+func TestNewDiffMergesRepeatedRuns(t *testing.T) {
+	baseline := &api.TestSuites{Suites: []*api.TestSuite{
+		{
+			Name: "package",
+			TestCases: []*api.TestCase{
+				{Name: "TestFlaky", Duration: 0.1},
+				{Name: "TestFlaky", Duration: 0.2},
+			},
+		},
+	}}
+	current := &api.TestSuites{Suites: []*api.TestSuite{
+		{
+			Name: "package",
+			TestCases: []*api.TestCase{
+				{Name: "TestFlaky", Duration: 0.1},
+				{Name: "TestFlaky", Duration: 0.3, FailureOutput: &api.FailureOutput{Message: "boom"}},
+			},
+		},
+	}}
+
+	expected := &Diff{
+		NewFailures: []TestCaseDiff{
+			{
+				Suite:            "package",
+				Name:             "TestFlaky",
+				BaselineResult:   api.TestResultPass,
+				Result:           api.TestResultFail,
+				BaselineDuration: 0.2,
+				Duration:         0.3,
+			},
+		},
+		Fixed:   []TestCaseDiff{},
+		Added:   []TestCaseDiff{},
+		Removed: []TestCaseDiff{},
+		Slower:  []TestCaseDiff{},
+	}
+	if actual := NewDiff(baseline, current, 2, 1); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("did not produce the correct difference:\n%s", diff.ObjectReflectDiff(expected, actual))
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+)
+
+const (
+	// redactedOutput replaces output matching a redaction rule
+	redactedOutput = "[REDACTED]"
+
+	// truncationMarker ends output that was truncated, with the number of bytes that were dropped
+	truncationMarker = "\n... [truncated %d bytes]"
+)
+
+// OutputFilter selects the test cases to report and redacts and truncates their output before the jUnit XML is
+// written, so that reports can be published without leaking credentials or internal details
+type OutputFilter struct {
+	// Redactions match output to be replaced with a redaction marker
+	Redactions []*regexp.Regexp
+
+	// MaxOutputBytes is the maximum size of every output of a test case, like its stdout or failure output. Output
+	// that is larger is truncated, with a marker recording how much was dropped. No output is truncated if zero.
+	MaxOutputBytes int
+
+	// Include matches the names of the test cases to report. All test cases are reported if empty.
+	Include []*regexp.Regexp
+
+	// Exclude matches the names of the test cases not to report, even if they are included
+	Exclude []*regexp.Regexp
+}
+
+// NewOutputFilter compiles the redaction rules and test case name patterns of an output filter
+func NewOutputFilter(redactions, include, exclude []string, maxOutputBytes int) (*OutputFilter, error) {
+	if maxOutputBytes < 0 {
+		return nil, fmt.Errorf("invalid maximum output size %d: expected a number of bytes that is not negative", maxOutputBytes)
+	}
+	filter := &OutputFilter{MaxOutputBytes: maxOutputBytes}
+
+	var err error
+	if filter.Redactions, err = compilePatterns("redaction rule", redactions); err != nil {
+		return nil, err
+	}
+	if filter.Include, err = compilePatterns("test case inclusion pattern", include); err != nil {
+		return nil, err
+	}
+	if filter.Exclude, err = compilePatterns("test case exclusion pattern", exclude); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// compilePatterns compiles the regular expressions of a filter
+func compilePatterns(kind string, patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", kind, pattern, err)
+		}
+		compiled = append(compiled, expression)
+	}
+	return compiled, nil
+}
+
+// Apply filters all of the test suites, dropping those left without test cases
+func (f *OutputFilter) Apply(testSuites *api.TestSuites) {
+	var suites []*api.TestSuite
+	for _, suite := range testSuites.Suites {
+		if f.FilterSuite(suite) {
+			suites = append(suites, suite)
+		}
+	}
+	testSuites.Suites = suites
+}
+
+// FilterSuite removes the test cases that are not selected from the suite and its children, and redacts and
+// truncates the output of those that remain. The counts of the suite are updated accordingly, while its duration
+// is left as it was recorded. FilterSuite returns false if the suite had test cases, but none were selected.
+func (f *OutputFilter) FilterSuite(suite *api.TestSuite) bool {
+	numTests := suite.NumTests
+
+	var children []*api.TestSuite
+	for _, child := range suite.Children {
+		// the counts of the suite include those of its children, so they are updated by what the child lost
+		childTests, childSkipped, childFailed, childErrored, childFlaky := child.NumTests, child.NumSkipped, child.NumFailed, child.NumErrored, child.NumFlaky
+		keep := f.FilterSuite(child)
+		suite.NumTests = suite.NumTests - childTests + child.NumTests
+		suite.NumSkipped = suite.NumSkipped - childSkipped + child.NumSkipped
+		suite.NumFailed = suite.NumFailed - childFailed + child.NumFailed
+		suite.NumErrored = suite.NumErrored - childErrored + child.NumErrored
+		suite.NumFlaky = suite.NumFlaky - childFlaky + child.NumFlaky
+		if keep {
+			children = append(children, child)
+		}
+	}
+	suite.Children = children
+
+	var testCases []*api.TestCase
+	for _, testCase := range suite.TestCases {
+		if !f.selects(testCase.Name) {
+			suite.NumTests -= 1
+			switch {
+			case testCase.SkipMessage != nil:
+				suite.NumSkipped -= 1
+			case testCase.FailureOutput != nil:
+				suite.NumFailed -= 1
+			case testCase.ErrorOutput != nil:
+				suite.NumErrored -= 1
+			}
+			if len(testCase.FlakyFailures) > 0 {
+				suite.NumFlaky -= 1
+			}
+			continue
+		}
+		f.filterOutput(testCase)
+		testCases = append(testCases, testCase)
+	}
+	suite.TestCases = testCases
+
+	return numTests == 0 || suite.NumTests > 0
+}
+
+// selects determines if the test case with the given name is to be reported
+func (f *OutputFilter) selects(name string) bool {
+	for _, exclude := range f.Exclude {
+		if exclude.MatchString(name) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, include := range f.Include {
+		if include.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterOutput redacts and truncates every output of the test case
+func (f *OutputFilter) filterOutput(testCase *api.TestCase) {
+	testCase.SystemOut = f.filter(testCase.SystemOut)
+	testCase.SystemErr = f.filter(testCase.SystemErr)
+	if testCase.SkipMessage != nil {
+		testCase.SkipMessage.Message = f.filter(testCase.SkipMessage.Message)
+	}
+	if testCase.FailureOutput != nil {
+		testCase.FailureOutput.Message = f.filter(testCase.FailureOutput.Message)
+		testCase.FailureOutput.Output = f.filter(testCase.FailureOutput.Output)
+	}
+	if testCase.ErrorOutput != nil {
+		testCase.ErrorOutput.Message = f.filter(testCase.ErrorOutput.Message)
+		testCase.ErrorOutput.Output = f.filter(testCase.ErrorOutput.Output)
+	}
+	for _, failures := range [][]*api.RerunFailure{testCase.RerunFailures, testCase.FlakyFailures} {
+		for _, failure := range failures {
+			failure.Message = f.filter(failure.Message)
+			failure.StackTrace = f.filter(failure.StackTrace)
+			failure.SystemOut = f.filter(failure.SystemOut)
+			failure.SystemErr = f.filter(failure.SystemErr)
+		}
+	}
+}
+
+// filter redacts the output and then truncates it, so that truncation cannot leave part of a secret unredacted
+func (f *OutputFilter) filter(output string) string {
+	for _, redaction := range f.Redactions {
+		output = redaction.ReplaceAllLiteralString(output, redactedOutput)
+	}
+	if f.MaxOutputBytes == 0 || len(output) <= f.MaxOutputBytes {
+		return output
+	}
+
+	// the output is not cut in the middle of a multi-byte character
+	end := f.MaxOutputBytes
+	for end > 0 && !utf8.RuneStart(output[end]) {
+		end--
+	}
+	return output[:end] + fmt.Sprintf(truncationMarker, len(output)-end)
+}
+
+// filteringBuilder filters every test suite before handing it to the underlying builder
+type filteringBuilder struct {
+	builder.TestSuitesBuilder
+
+	filter *OutputFilter
+}
+
+func (b *filteringBuilder) AddSuite(suite *api.TestSuite) {
+	if b.filter.FilterSuite(suite) {
+		b.TestSuitesBuilder.AddSuite(suite)
+	}
+}
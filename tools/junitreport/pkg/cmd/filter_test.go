@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+func TestOutputFilterRedactsAndTruncates(t *testing.T) {
+	filter, err := NewOutputFilter([]string{`token=\S+`, `[a-z]+\.internal\.example\.com`}, nil, nil, 40)
+	if err != nil {
+		t.Fatalf("unexpected error creating filter: %v", err)
+	}
+
+	testSuites := &api.TestSuites{
+		Suites: []*api.TestSuite{
+			{
+				Name:      "package",
+				NumTests:  2,
+				NumFailed: 1,
+				TestCases: []*api.TestCase{
+					{
+						Name: "TestFails",
+						FailureOutput: &api.FailureOutput{
+							Message: "could not reach db.internal.example.com",
+							Output:  "login with token=abc123 failed",
+						},
+						SystemOut: "ééééééééééééééééééééééé",
+						FlakyFailures: []*api.RerunFailure{
+							{StackTrace: "token=def456"},
+						},
+					},
+					{
+						Name: "TestSkips",
+						SkipMessage: &api.SkipMessage{
+							Message: "needs token=ghi789",
+						},
+					},
+				},
+			},
+		},
+	}
+	filter.Apply(testSuites)
+
+	expected := &api.TestSuites{
+		Suites: []*api.TestSuite{
+			{
+				Name:      "package",
+				NumTests:  2,
+				NumFailed: 1,
+				TestCases: []*api.TestCase{
+					{
+						Name: "TestFails",
+						FailureOutput: &api.FailureOutput{
+							Message: "could not reach [REDACTED]",
+							Output:  "login with [REDACTED] failed",
+						},
+						SystemOut: "éééééééééééééééééééé\n... [truncated 6 bytes]",
+						FlakyFailures: []*api.RerunFailure{
+							{StackTrace: "[REDACTED]"},
+						},
+					},
+					{
+						Name: "TestSkips",
+						SkipMessage: &api.SkipMessage{
+							Message: "needs [REDACTED]",
+						},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(testSuites, expected) {
+		t.Errorf("did not filter the output correctly:\n%s", diff.ObjectReflectDiff(expected, testSuites))
+	}
+}
+
+func TestOutputFilterSelectsTestCases(t *testing.T) {
+	filter, err := NewOutputFilter(nil, []string{`^TestE2E`}, []string{`Serial`}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error creating filter: %v", err)
+	}
+
+	testSuites := &api.TestSuites{
+		Suites: []*api.TestSuite{
+			{
+				Name:       "root",
+				NumTests:   5,
+				NumSkipped: 1,
+				NumFailed:  2,
+				NumFlaky:   1,
+				Duration:   5,
+				TestCases: []*api.TestCase{
+					{Name: "TestE2EFast", Duration: 1},
+				},
+				Children: []*api.TestSuite{
+					{
+						Name:       "root/e2e",
+						NumTests:   3,
+						NumSkipped: 1,
+						NumFailed:  1,
+						NumFlaky:   1,
+						Duration:   3,
+						TestCases: []*api.TestCase{
+							{Name: "TestE2ESerial", Duration: 1, SkipMessage: &api.SkipMessage{}},
+							{Name: "TestE2EFlaky", Duration: 1, FlakyFailures: []*api.RerunFailure{{}}},
+							{Name: "TestE2EBroken", Duration: 1, FailureOutput: &api.FailureOutput{}},
+						},
+					},
+					{
+						Name:      "root/unit",
+						NumTests:  1,
+						NumFailed: 1,
+						Duration:  1,
+						TestCases: []*api.TestCase{
+							{Name: "TestUnit", Duration: 1, FailureOutput: &api.FailureOutput{}},
+						},
+					},
+				},
+			},
+			{
+				Name:     "other",
+				NumTests: 1,
+				TestCases: []*api.TestCase{
+					{Name: "TestOther"},
+				},
+			},
+		},
+	}
+	filter.Apply(testSuites)
+
+	expected := &api.TestSuites{
+		Suites: []*api.TestSuite{
+			{
+				Name:      "root",
+				NumTests:  3,
+				NumFailed: 1,
+				NumFlaky:  1,
+				Duration:  5,
+				TestCases: []*api.TestCase{
+					{Name: "TestE2EFast", Duration: 1},
+				},
+				Children: []*api.TestSuite{
+					{
+						Name:      "root/e2e",
+						NumTests:  2,
+						NumFailed: 1,
+						NumFlaky:  1,
+						Duration:  3,
+						TestCases: []*api.TestCase{
+							{Name: "TestE2EFlaky", Duration: 1, FlakyFailures: []*api.RerunFailure{{}}},
+							{Name: "TestE2EBroken", Duration: 1, FailureOutput: &api.FailureOutput{}},
+						},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(testSuites, expected) {
+		t.Errorf("did not select the correct test cases:\n%s", diff.ObjectReflectDiff(expected, testSuites))
+	}
+}
+
+func TestNewOutputFilterValidates(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		redactions     []string
+		include        []string
+		exclude        []string
+		maxOutputBytes int
+		expectedError  string
+	}{
+		{
+			name:          "invalid redaction rule",
+			redactions:    []string{"token=("},
+			expectedError: `invalid redaction rule "token=("`,
+		},
+		{
+			name:          "invalid inclusion pattern",
+			include:       []string{"[Test"},
+			expectedError: `invalid test case inclusion pattern "[Test"`,
+		},
+		{
+			name:          "invalid exclusion pattern",
+			exclude:       []string{"*"},
+			expectedError: `invalid test case exclusion pattern "*"`,
+		},
+		{
+			name:           "negative maximum output size",
+			maxOutputBytes: -1,
+			expectedError:  "invalid maximum output size -1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewOutputFilter(testCase.redactions, testCase.include, testCase.exclude, testCase.maxOutputBytes)
+			if err == nil || !strings.HasPrefix(err.Error(), testCase.expectedError) {
+				t.Errorf("expected an error starting with %q, got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+type HTMLOptions struct {
+	// Title is the title of the HTML page
+	Title string
+
+	// NumSlowest is the number of test cases and test suites listed in the tables of the slowest ones
+	NumSlowest int
+
+	// Input is the reader for the jUnit XML to be rendered
+	Input io.Reader
+
+	// Output is the writer for the HTML page
+	Output io.Writer
+}
+
+func (o *HTMLOptions) Complete(title string, numSlowest int) error {
+	if numSlowest < 0 {
+		return fmt.Errorf("invalid number of slowest tests %d: expected a number that is not negative", numSlowest)
+	}
+	o.Title = title
+	o.NumSlowest = numSlowest
+
+	return nil
+}
+
+func (o *HTMLOptions) Run() error {
+	testSuites, err := decodeTestSuites(o.Input)
+	if err != nil {
+		return err
+	}
+
+	return RenderHTML(o.Output, testSuites, o.Title, o.NumSlowest)
+}
+
+// htmlReport is the view of a jUnit XML file rendered as an HTML page
+type htmlReport struct {
+	Title string
+	htmlCounts
+	Suites        []*htmlSuite
+	SlowestTests  []*htmlTestCase
+	SlowestSuites []*htmlSuite
+}
+
+// htmlCounts holds the number of tests with every result and the time taken to run them
+type htmlCounts struct {
+	NumTests   uint
+	NumPassed  uint
+	NumFailed  uint
+	NumSkipped uint
+	NumFlaky   uint
+	Duration   float64
+}
+
+// htmlSuite is the view of a test suite
+type htmlSuite struct {
+	Name string
+	htmlCounts
+	TestCases []*htmlTestCase
+	Children  []*htmlSuite
+}
+
+// htmlTestCase is the view of a test case
+type htmlTestCase struct {
+	Suite    string
+	Name     string
+	Result   api.TestResult
+	Flaky    bool
+	Duration float64
+	// Message is the failure, error or skip message of the test case
+	Message string
+	// Output is the output recorded for the test case, if any
+	Output string
+}
+
+// RenderHTML renders the test suites as a single HTML page that does not depend on any external resources, with a
+// collapsible tree of test suites and tables of the slowest test cases and test suites
+func RenderHTML(output io.Writer, testSuites *api.TestSuites, title string, numSlowest int) error {
+	report := &htmlReport{Title: title}
+	var testCases []*htmlTestCase
+	var suites []*htmlSuite
+	for _, suite := range testSuites.Suites {
+		view := newHTMLSuite(suite, &testCases, &suites)
+		report.Suites = append(report.Suites, view)
+		report.NumTests += view.NumTests
+		report.NumPassed += view.NumPassed
+		report.NumFailed += view.NumFailed
+		report.NumSkipped += view.NumSkipped
+		report.NumFlaky += view.NumFlaky
+		report.Duration += view.Duration
+	}
+
+	sort.SliceStable(testCases, func(i, j int) bool {
+		return testCases[i].Duration > testCases[j].Duration
+	})
+	if len(testCases) > numSlowest {
+		testCases = testCases[:numSlowest]
+	}
+	report.SlowestTests = testCases
+
+	// only suites holding test cases themselves are listed, as their parents would always be slower
+	var leafSuites []*htmlSuite
+	for _, suite := range suites {
+		if len(suite.TestCases) > 0 {
+			leafSuites = append(leafSuites, suite)
+		}
+	}
+	sort.SliceStable(leafSuites, func(i, j int) bool {
+		return leafSuites[i].Duration > leafSuites[j].Duration
+	})
+	if len(leafSuites) > numSlowest {
+		leafSuites = leafSuites[:numSlowest]
+	}
+	report.SlowestSuites = leafSuites
+
+	return htmlTemplate.Execute(output, report)
+}
+
+// newHTMLSuite builds the view of a test suite and the suites nested under it, collecting all test cases and suites
+func newHTMLSuite(suite *api.TestSuite, testCases *[]*htmlTestCase, suites *[]*htmlSuite) *htmlSuite {
+	view := &htmlSuite{
+		Name: suite.Name,
+		htmlCounts: htmlCounts{
+			NumTests:   suite.NumTests,
+			NumFailed:  suite.NumFailed + suite.NumErrored,
+			NumSkipped: suite.NumSkipped,
+			NumFlaky:   suite.NumFlaky,
+			Duration:   suite.Duration,
+		},
+	}
+	if view.NumFailed+view.NumSkipped <= view.NumTests {
+		view.NumPassed = view.NumTests - view.NumFailed - view.NumSkipped
+	}
+	*suites = append(*suites, view)
+
+	for _, testCase := range suite.TestCases {
+		testCaseView := newHTMLTestCase(suite.Name, testCase)
+		view.TestCases = append(view.TestCases, testCaseView)
+		*testCases = append(*testCases, testCaseView)
+	}
+	for _, child := range suite.Children {
+		view.Children = append(view.Children, newHTMLSuite(child, testCases, suites))
+	}
+	return view
+}
+
+// newHTMLTestCase builds the view of a test case
+func newHTMLTestCase(suite string, testCase *api.TestCase) *htmlTestCase {
+	view := &htmlTestCase{
+		Suite:    suite,
+		Name:     testCase.Name,
+		Result:   testCase.Result(),
+		Flaky:    len(testCase.FlakyFailures) > 0,
+		Duration: testCase.Duration,
+	}
+
+	var output []string
+	switch {
+	case testCase.SkipMessage != nil:
+		view.Message = testCase.SkipMessage.Message
+	case testCase.FailureOutput != nil:
+		view.Message = testCase.FailureOutput.Message
+		output = append(output, testCase.FailureOutput.Output)
+	case testCase.ErrorOutput != nil:
+		view.Message = testCase.ErrorOutput.Message
+		output = append(output, testCase.ErrorOutput.Output)
+	}
+	if i := strings.Index(view.Message, "\n"); i >= 0 {
+		// only the first line of a long message fits in the summary of the test case, so it is shown in full below
+		output = append([]string{view.Message}, output...)
+		view.Message = view.Message[:i]
+	}
+	output = append(output, testCase.SystemOut, testCase.SystemErr)
+	// the failed attempts of a test that was run more than once follow the output of the attempt it is reported as
+	for _, failure := range append(testCase.FlakyFailures, testCase.RerunFailures...) {
+		output = append(output, failure.Message, failure.StackTrace, failure.SystemOut, failure.SystemErr)
+	}
+
+	var nonEmpty []string
+	for _, section := range output {
+		if len(strings.TrimSpace(section)) > 0 {
+			nonEmpty = append(nonEmpty, section)
+		}
+	}
+	view.Output = strings.Join(nonEmpty, "\n\n")
+	return view
+}
+
+// formatDuration formats a duration in seconds for display
+func formatDuration(duration float64) string {
+	return fmt.Sprintf("%.3fs", duration)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": formatDuration,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+h1, h2 { font-weight: 600; }
+details { margin-left: 1em; }
+summary { cursor: pointer; padding: 0.2em 0; }
+pre { background: #f6f8fa; padding: 0.8em; overflow-x: auto; white-space: pre-wrap; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #d1d5da; padding: 0.3em 0.8em; text-align: left; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th[data-order="ascending"]::after { content: " \25B2"; }
+th[data-order="descending"]::after { content: " \25BC"; }
+.badge { display: inline-block; border-radius: 0.8em; padding: 0 0.6em; margin-right: 0.3em; font-size: 0.85em; color: #fff; }
+.pass { background: #28a745; }
+.fail { background: #d73a49; }
+.skip { background: #6a737d; }
+.flaky { background: #dbab09; }
+.duration { color: #6a737d; font-size: 0.85em; }
+.message { color: #d73a49; margin-left: 1em; }
+.testcase { margin-left: 1em; padding: 0.2em 0; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>
+{{- template "counts" . }}
+<span class="duration">{{ duration .Duration }}</span>
+</p>
+
+<h2>Test suites</h2>
+{{- range .Suites }}
+{{ template "suite" . }}
+{{- else }}
+<p>No test suites were recorded.</p>
+{{- end }}
+
+{{- if .SlowestTests }}
+
+<h2>Slowest test cases</h2>
+<table class="sortable">
+<thead><tr><th data-type="text">Suite</th><th data-type="text">Test case</th><th data-type="text">Result</th><th data-type="number" data-order="descending">Duration</th></tr></thead>
+<tbody>
+{{- range .SlowestTests }}
+<tr><td>{{ .Suite }}</td><td>{{ .Name }}</td><td>{{ template "result" . }}</td><td data-value="{{ .Duration }}">{{ duration .Duration }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- end }}
+
+{{- if .SlowestSuites }}
+
+<h2>Slowest test suites</h2>
+<table class="sortable">
+<thead><tr><th data-type="text">Suite</th><th data-type="number">Tests</th><th data-type="number">Failed</th><th data-type="number">Skipped</th><th data-type="number" data-order="descending">Duration</th></tr></thead>
+<tbody>
+{{- range .SlowestSuites }}
+<tr><td>{{ .Name }}</td><td data-value="{{ .NumTests }}">{{ .NumTests }}</td><td data-value="{{ .NumFailed }}">{{ .NumFailed }}</td><td data-value="{{ .NumSkipped }}">{{ .NumSkipped }}</td><td data-value="{{ .Duration }}">{{ duration .Duration }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- end }}
+
+<script>
+document.querySelectorAll("table.sortable th").forEach(function (header) {
+	header.addEventListener("click", function () {
+		var table = header.closest("table");
+		var body = table.tBodies[0];
+		var column = header.cellIndex;
+		var numeric = header.getAttribute("data-type") === "number";
+		var order = header.getAttribute("data-order") === "descending" ? "ascending" : "descending";
+		table.querySelectorAll("th").forEach(function (other) { other.removeAttribute("data-order"); });
+		header.setAttribute("data-order", order);
+
+		var rows = Array.prototype.slice.call(body.rows);
+		rows.sort(function (a, b) {
+			var left = a.cells[column], right = b.cells[column];
+			var comparison = numeric
+				? parseFloat(left.getAttribute("data-value")) - parseFloat(right.getAttribute("data-value"))
+				: left.textContent.localeCompare(right.textContent);
+			return order === "ascending" ? comparison : -comparison;
+		});
+		rows.forEach(function (row) { body.appendChild(row); });
+	});
+});
+</script>
+</body>
+</html>
+
+{{- define "counts" -}}
+<span class="badge pass">{{ .NumPassed }} passed</span>
+{{- if .NumFailed }}<span class="badge fail">{{ .NumFailed }} failed</span>{{ end }}
+{{- if .NumSkipped }}<span class="badge skip">{{ .NumSkipped }} skipped</span>{{ end }}
+{{- if .NumFlaky }}<span class="badge flaky">{{ .NumFlaky }} flaky</span>{{ end }}
+{{- end }}
+
+{{- define "result" }}
+{{- if eq .Result "fail" }}<span class="badge fail">failed</span>
+{{- else if eq .Result "skip" }}<span class="badge skip">skipped</span>
+{{- else }}<span class="badge pass">passed</span>{{ end }}
+{{- if .Flaky }}<span class="badge flaky">flaky</span>{{ end }}
+{{- end }}
+
+{{- define "suite" }}
+<details{{ if .NumFailed }} open{{ end }}>
+<summary><strong>{{ .Name }}</strong> {{ template "counts" . }} <span class="duration">{{ duration .Duration }}</span></summary>
+{{- range .TestCases }}
+{{- if or .Message .Output }}
+<details class="testcase"{{ if eq .Result "fail" }} open{{ end }}>
+<summary>{{ template "result" . }} {{ .Name }} <span class="duration">{{ duration .Duration }}</span>{{ if .Message }} <span class="message">{{ .Message }}</span>{{ end }}</summary>
+{{- if .Output }}
+<pre>{{ .Output }}</pre>
+{{- end }}
+</details>
+{{- else }}
+<div class="testcase">{{ template "result" . }} {{ .Name }} <span class="duration">{{ duration .Duration }}</span></div>
+{{- end }}
+{{- end }}
+{{- range .Children }}
+{{ template "suite" . }}
+{{- end }}
+</details>
+{{- end }}
+`))
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+const htmlReportXML = `<testsuites>
+	<testsuite name="package" tests="4" skipped="1" failures="1" flakes="1" time="3.5">
+		<testsuite name="package/slow" tests="2" skipped="0" failures="1" flakes="1" time="3">
+			<testcase name="TestBroken" time="1"><failure message="expected &lt;nil&gt;">stack trace</failure></testcase>
+			<testcase name="TestFlaky" time="2"><flakyFailure message="timed out"></flakyFailure></testcase>
+		</testsuite>
+		<testsuite name="package/fast" tests="2" skipped="1" failures="0" time="0.5">
+			<testcase name="TestFast" time="0.5"></testcase>
+			<testcase name="TestSkipped" time="0"><skipped message="first line&#xA;second line"></skipped></testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>`
+
+func TestHTMLOptionsRun(t *testing.T) {
+	var output strings.Builder
+	options := HTMLOptions{
+		Input:  strings.NewReader(htmlReportXML),
+		Output: &output,
+	}
+	if err := options.Complete("Unit <tests>", 2); err != nil {
+		t.Fatalf("unexpected error completing options: %v", err)
+	}
+	if err := options.Run(); err != nil {
+		t.Fatalf("unexpected error rendering HTML: %v", err)
+	}
+	report := output.String()
+
+	for _, expected := range []string{
+		"<title>Unit &lt;tests&gt;</title>",
+		`<span class="badge pass">2 passed</span><span class="badge fail">1 failed</span><span class="badge skip">1 skipped</span><span class="badge flaky">1 flaky</span>`,
+		"<details open>\n<summary><strong>package/slow</strong>",
+		"<details>\n<summary><strong>package/fast</strong>",
+		`<summary><span class="badge fail">failed</span> TestBroken <span class="duration">1.000s</span> <span class="message">expected &lt;nil&gt;</span></summary>`,
+		"<pre>stack trace</pre>",
+		"TestFlaky <span class=\"duration\">2.000s</span></summary>\n<pre>timed out</pre>",
+		`<span class="message">first line</span></summary>` + "\n<pre>first line\nsecond line</pre>",
+		`<tr><td>package/slow</td><td>TestFlaky</td><td><span class="badge pass">passed</span><span class="badge flaky">flaky</span></td><td data-value="2">2.000s</td></tr>` + "\n" +
+			`<tr><td>package/slow</td><td>TestBroken</td><td><span class="badge fail">failed</span></td><td data-value="1">1.000s</td></tr>` + "\n</tbody>",
+		`<tr><td>package/slow</td><td data-value="2">2</td><td data-value="1">1</td><td data-value="0">0</td><td data-value="3">3.000s</td></tr>` + "\n" +
+			`<tr><td>package/fast</td><td data-value="2">2</td><td data-value="0">0</td><td data-value="1">1</td><td data-value="0.5">0.500s</td></tr>`,
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("expected the HTML report to contain %q, got:\n%s", expected, report)
+		}
+	}
+
+	// the report needs to be self-contained to be stored as an artifact
+	for _, unexpected := range []string{"<link", "<script src", "http://", "https://", "<tr><td>package</td>"} {
+		if strings.Contains(report, unexpected) {
+			t.Errorf("expected the HTML report not to contain %q", unexpected)
+		}
+	}
+}
+
+func TestHTMLOptionsRunSingleSuite(t *testing.T) {
+	var output strings.Builder
+	options := HTMLOptions{
+		Input: strings.NewReader(`<testsuite name="package" tests="2" skipped="0" failures="1" time="0.3">
+	<testcase name="TestPass" time="0.1"></testcase>
+	<testcase name="TestFail" time="0.2"><failure message="boom">output</failure></testcase>
+</testsuite>`),
+		Output: &output,
+	}
+	if err := options.Complete("Unit tests", 2); err != nil {
+		t.Fatalf("unexpected error completing options: %v", err)
+	}
+	if err := options.Run(); err != nil {
+		t.Fatalf("unexpected error rendering HTML: %v", err)
+	}
+
+	expected := `<span class="badge pass">1 passed</span><span class="badge fail">1 failed</span>`
+	if !strings.Contains(output.String(), expected) {
+		t.Errorf("expected the HTML report to contain %q, got:\n%s", expected, output.String())
+	}
+}
+
+func TestHTMLOptionsComplete(t *testing.T) {
+	options := HTMLOptions{}
+	if err := options.Complete("Test report", -1); err == nil {
+		t.Errorf("expected an error for a negative number of slowest tests")
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+)
+
+// TestOutput is the test output held in one input file
+type TestOutput struct {
+	// Name is the path of the file holding the test output
+	Name string
+
+	// Reader is the reader for the test output
+	Reader io.Reader
+}
+
+// ExpandInputFiles returns the paths of the files matching the given paths or glob patterns, in the order in which
+// the patterns are given. The files matching one pattern are sorted by name and files matching more than one pattern
+// are only returned once. It is an error for a pattern not to match any file or to match a directory.
+func ExpandInputFiles(patterns []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input file pattern %q: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			if strings.ContainsAny(pattern, `*?[\`) {
+				return nil, fmt.Errorf("no input files match %q", pattern)
+			}
+			// report why the file cannot be read, like that it does not exist or cannot be accessed
+			if _, err := os.Stat(pattern); err != nil {
+				return nil, err
+			}
+			matches = []string{pattern}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				return nil, fmt.Errorf("input file %s is a directory", match)
+			}
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+			paths = append(paths, match)
+		}
+	}
+	return paths, nil
+}
+
+// suiteNamespace returns the namespace of the test suites parsed from a file, which is the name of the file without
+// its directory and extension, e.g. `shard-1` for `logs/shard-1.txt`
+func suiteNamespace(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// namespacingBuilder prefixes the names of all test suites with a namespace before handing them to the underlying
+// builder, so that suites with the same name parsed from different inputs are kept apart
+type namespacingBuilder struct {
+	builder.TestSuitesBuilder
+
+	namespace string
+}
+
+func (b *namespacingBuilder) AddSuite(suite *api.TestSuite) {
+	addNamespace(suite, b.namespace)
+	b.TestSuitesBuilder.AddSuite(suite)
+}
+
+// addNamespace prefixes the names of the test suite and the suites nested under it with the namespace
+func addNamespace(suite *api.TestSuite, namespace string) {
+	suite.Name = namespace + "/" + suite.Name
+	for _, child := range suite.Children {
+		addNamespace(child, namespace)
+	}
+}
+
+// mergingBuilder merges test suites with the same name parsed from different inputs, like a package whose tests are
+// split across shards, before handing them to the underlying builder, which would otherwise only keep the last of
+// them. A test suite added again while parsing the same input is handed to the underlying builder as usual.
+type mergingBuilder struct {
+	builder.TestSuitesBuilder
+
+	// suites are the test suites handed to the underlying builder by name
+	suites map[string]*api.TestSuite
+
+	// inputs are the indices of the inputs the test suites were parsed from by name
+	inputs map[string]int
+
+	// input is the index of the input being parsed
+	input int
+}
+
+func newMergingBuilder(testSuitesBuilder builder.TestSuitesBuilder) *mergingBuilder {
+	return &mergingBuilder{
+		TestSuitesBuilder: testSuitesBuilder,
+		suites:            map[string]*api.TestSuite{},
+		inputs:            map[string]int{},
+	}
+}
+
+func (b *mergingBuilder) AddSuite(suite *api.TestSuite) {
+	if added, exists := b.suites[suite.Name]; exists && b.inputs[suite.Name] != b.input {
+		// the underlying builder holds the suite that was added first, so the other suite is merged into it
+		mergeSuites(added, suite)
+		return
+	}
+	b.suites[suite.Name] = suite
+	b.inputs[suite.Name] = b.input
+	b.TestSuitesBuilder.AddSuite(suite)
+}
+
+// mergeSuites adds the test cases, nested suites and properties of the other test suite to the test suite. Test
+// cases with the same name, like a test that was retried in another shard, are merged as attempts of the same test.
+func mergeSuites(suite, other *api.TestSuite) {
+	var names []string
+	attempts := map[string][]*api.TestCase{}
+	for _, testCase := range append(suite.TestCases, other.TestCases...) {
+		if _, exists := attempts[testCase.Name]; !exists {
+			names = append(names, testCase.Name)
+		}
+		attempts[testCase.Name] = append(attempts[testCase.Name], testCase)
+	}
+	children := append(suite.Children, other.Children...)
+	// the durations of the suites include time spent outside of their test cases, like setting up a package
+	duration := suite.Duration + other.Duration
+
+	suite.NumTests, suite.NumSkipped, suite.NumFailed, suite.NumErrored, suite.NumFlaky = 0, 0, 0, 0, 0
+	suite.TestCases, suite.Children = nil, nil
+	for _, name := range names {
+		suite.AddTestCase(api.MergeAttempts(attempts[name]))
+	}
+	for _, child := range children {
+		suite.AddChild(child)
+	}
+	// we round to the millisecond on duration
+	suite.Duration = math.Round(duration*1000) / 1000
+
+	for _, property := range other.Properties {
+		suite.AddProperty(property.Name, property.Value)
+	}
+}
+
+// sharedBuilder hands test suites to a builder shared by the parsers of several inputs. Building is left to the
+// owner of the shared builder once all inputs have been parsed, as builders can only build their test suites once.
+type sharedBuilder struct {
+	builder.TestSuitesBuilder
+}
+
+func (b *sharedBuilder) Build() *api.TestSuites {
+	return &api.TestSuites{}
+}
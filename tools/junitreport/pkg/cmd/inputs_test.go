@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+func TestExpandInputFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junitreport-inputs")
+	if err != nil {
+		t.Fatalf("unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"shard-2.txt", "shard-1.txt", "other.log"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("output\n"), 0644); err != nil {
+			t.Fatalf("unexpected error writing input file: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "shard-3.txt"), 0755); err != nil {
+		t.Fatalf("unexpected error creating directory: %v", err)
+	}
+
+	var testCases = []struct {
+		name          string
+		patterns      []string
+		expectedPaths []string
+		expectedError string
+	}{
+		{
+			name:          "paths and globs in order",
+			patterns:      []string{filepath.Join(dir, "other.log"), filepath.Join(dir, "shard-[12].txt")},
+			expectedPaths: []string{filepath.Join(dir, "other.log"), filepath.Join(dir, "shard-1.txt"), filepath.Join(dir, "shard-2.txt")},
+		},
+		{
+			name:          "files matched twice",
+			patterns:      []string{filepath.Join(dir, "shard-2.txt"), filepath.Join(dir, "shard-[12].txt")},
+			expectedPaths: []string{filepath.Join(dir, "shard-2.txt"), filepath.Join(dir, "shard-1.txt")},
+		},
+		{
+			name:          "missing file",
+			patterns:      []string{filepath.Join(dir, "shard-1.txt"), filepath.Join(dir, "missing.txt")},
+			expectedError: "stat " + filepath.Join(dir, "missing.txt") + ": no such file or directory",
+		},
+		{
+			name:          "glob without matches",
+			patterns:      []string{filepath.Join(dir, "*.xml")},
+			expectedError: `no input files match "` + filepath.Join(dir, "*.xml") + `"`,
+		},
+		{
+			name:          "directory",
+			patterns:      []string{filepath.Join(dir, "shard-*.txt")},
+			expectedError: "input file " + filepath.Join(dir, "shard-3.txt") + " is a directory",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			paths, err := ExpandInputFiles(testCase.patterns)
+			if len(testCase.expectedError) > 0 {
+				if err == nil || err.Error() != testCase.expectedError {
+					t.Errorf("expected error %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error expanding input files: %v", err)
+			}
+			if !reflect.DeepEqual(paths, testCase.expectedPaths) {
+				t.Errorf("did not expand the input files correctly:\n%s", diff.ObjectReflectDiff(testCase.expectedPaths, paths))
+			}
+		})
+	}
+}
+
+func TestJUnitReportOptionsRunMultipleInputs(t *testing.T) {
+	shardOne := `=== RUN   TestOne
+--- PASS: TestOne (0.06 seconds)
+PASS
+ok  	package/name 0.160s
+`
+	shardTwo := `=== RUN   TestOne
+--- FAIL: TestOne (0.02 seconds)
+	file_test.go:11: Error message
+FAIL
+exit status 1
+FAIL	package/name 0.150s
+`
+
+	var testCases = []struct {
+		name            string
+		builderType     string
+		namespaceSuites bool
+		incremental     bool
+		expectedOutput  string
+	}{
+		{
+			name:        "flat",
+			builderType: "flat",
+			expectedOutput: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite name="package/name" tests="1" skipped="0" failures="0" time="0.16">
+		<testcase name="TestOne" time="0.06"></testcase>
+	</testsuite>
+	<testsuite name="package/name" tests="1" skipped="0" failures="1" time="0.15">
+		<testcase name="TestOne" time="0.02">
+			<failure message="">file_test.go:11: Error message</failure>
+		</testcase>
+	</testsuite>
+</testsuites>
+`,
+		},
+		{
+			name:        "incremental",
+			builderType: "flat",
+			incremental: true,
+			expectedOutput: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite name="package/name" tests="1" skipped="0" failures="0" time="0.16">
+		<testcase name="TestOne" time="0.06"></testcase>
+	</testsuite>
+	<testsuite name="package/name" tests="1" skipped="0" failures="1" time="0.15">
+		<testcase name="TestOne" time="0.02">
+			<failure message="">file_test.go:11: Error message</failure>
+		</testcase>
+	</testsuite>
+</testsuites>
+`,
+		},
+		{
+			name:        "nested",
+			builderType: "nested",
+			expectedOutput: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite name="package" tests="1" skipped="0" failures="0" flakes="1" time="0.31">
+		<testsuite name="package/name" tests="1" skipped="0" failures="0" flakes="1" time="0.31">
+			<testcase name="TestOne" time="0.08">
+				<flakyFailure message="">
+					<stackTrace>file_test.go:11: Error message</stackTrace>
+				</flakyFailure>
+			</testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>
+`,
+		},
+		{
+			name:            "nested with namespaces",
+			builderType:     "nested",
+			namespaceSuites: true,
+			expectedOutput: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite name="shard-1" tests="1" skipped="0" failures="0" time="0.16">
+		<testsuite name="shard-1/package" tests="1" skipped="0" failures="0" time="0.16">
+			<testsuite name="shard-1/package/name" tests="1" skipped="0" failures="0" time="0.16">
+				<testcase name="TestOne" time="0.06"></testcase>
+			</testsuite>
+		</testsuite>
+	</testsuite>
+	<testsuite name="shard-2" tests="1" skipped="0" failures="1" time="0.15">
+		<testsuite name="shard-2/package" tests="1" skipped="0" failures="1" time="0.15">
+			<testsuite name="shard-2/package/name" tests="1" skipped="0" failures="1" time="0.15">
+				<testcase name="TestOne" time="0.02">
+					<failure message="">file_test.go:11: Error message</failure>
+				</testcase>
+			</testsuite>
+		</testsuite>
+	</testsuite>
+</testsuites>
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var output strings.Builder
+			options := JUnitReportOptions{
+				NamespaceSuites: testCase.namespaceSuites,
+				Incremental:     testCase.incremental,
+				Inputs: []TestOutput{
+					{Name: "logs/shard-1.txt", Reader: strings.NewReader(shardOne)},
+					{Name: "logs/shard-2.txt", Reader: strings.NewReader(shardTwo)},
+				},
+				Output: &output,
+			}
+			if err := options.Complete(testCase.builderType, "gotest", nil); err != nil {
+				t.Fatalf("unexpected error completing options: %v", err)
+			}
+			if err := options.Run(); err != nil {
+				t.Fatalf("unexpected error generating output: %v", err)
+			}
+			if output.String() != testCase.expectedOutput {
+				t.Errorf("did not generate the correct jUnit XML:\n%s", diff.ObjectReflectDiff(testCase.expectedOutput, output.String()))
+			}
+		})
+	}
+}
+
+func TestJUnitReportOptionsCompleteRejectsSharedNamespaces(t *testing.T) {
+	options := JUnitReportOptions{
+		NamespaceSuites: true,
+		Inputs: []TestOutput{
+			{Name: "logs/a/shard-1.txt", Reader: strings.NewReader("")},
+			{Name: "logs/b/shard-1.txt", Reader: strings.NewReader("")},
+		},
+	}
+	err := options.Complete("flat", "gotest", nil)
+	expected := `input files logs/a/shard-1.txt and logs/b/shard-1.txt have the same test suite namespace "shard-1"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestMergingBuilder(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		inputs         []int
+		suites         []*api.TestSuite
+		expectedSuites *api.TestSuites
+	}{
+		{
+			name:   "different inputs",
+			inputs: []int{0, 1},
+			suites: []*api.TestSuite{
+				{
+					Name:       "package/name",
+					NumTests:   2,
+					NumFailed:  1,
+					Duration:   0.2,
+					Properties: []*api.TestSuiteProperty{{Name: "coverage.statements.pct", Value: "10.0"}},
+					TestCases: []*api.TestCase{
+						{Name: "TestOne", Duration: 0.05},
+						{Name: "TestTwo", Duration: 0.1, FailureOutput: &api.FailureOutput{Output: "boom"}},
+					},
+				},
+				{
+					Name:       "package/name",
+					NumTests:   2,
+					Duration:   0.25,
+					Properties: []*api.TestSuiteProperty{{Name: "coverage.statements.pct", Value: "20.0"}},
+					TestCases: []*api.TestCase{
+						{Name: "TestTwo", Duration: 0.1},
+						{Name: "TestThree", Duration: 0.1},
+					},
+				},
+			},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:       "package/name",
+						NumTests:   3,
+						NumFlaky:   1,
+						Duration:   0.45,
+						Properties: []*api.TestSuiteProperty{{Name: "coverage.statements.pct", Value: "20.0"}},
+						TestCases: []*api.TestCase{
+							{Name: "TestOne", Duration: 0.05},
+							{Name: "TestTwo", Duration: 0.2, FlakyFailures: []*api.RerunFailure{{StackTrace: "boom"}}},
+							{Name: "TestThree", Duration: 0.1},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "same input",
+			inputs: []int{0, 0},
+			suites: []*api.TestSuite{
+				{
+					Name:      "package/name",
+					NumTests:  1,
+					TestCases: []*api.TestCase{{Name: "TestOne"}},
+				},
+				{
+					Name:      "package/name",
+					NumTests:  1,
+					TestCases: []*api.TestCase{{Name: "TestTwo"}},
+				},
+			},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  1,
+						TestCases: []*api.TestCase{{Name: "TestTwo"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testSuitesBuilder := &recordingBuilder{}
+			merger := newMergingBuilder(testSuitesBuilder)
+			for i, suite := range testCase.suites {
+				merger.input = testCase.inputs[i]
+				merger.AddSuite(suite)
+			}
+			if testSuites := testSuitesBuilder.Build(); !reflect.DeepEqual(testSuites, testCase.expectedSuites) {
+				t.Errorf("did not merge the test suites correctly:\n%s", diff.ObjectReflectDiff(testCase.expectedSuites, testSuites))
+			}
+		})
+	}
+}
+
+// recordingBuilder keeps the last test suite added with every name, like the nested test suites builder
+type recordingBuilder struct {
+	suites []*api.TestSuite
+}
+
+func (b *recordingBuilder) AddSuite(suite *api.TestSuite) {
+	for i, added := range b.suites {
+		if added.Name == suite.Name {
+			b.suites[i] = suite
+			return
+		}
+	}
+	b.suites = append(b.suites, suite)
+}
+
+func (b *recordingBuilder) Build() *api.TestSuites {
+	return &api.TestSuites{Suites: b.suites}
+}
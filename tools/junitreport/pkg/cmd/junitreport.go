@@ -1,37 +1,39 @@
 package cmd
 
 import (
-	"bufio"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"sync"
 
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
 	"github.com/openshift/origin/tools/junitreport/pkg/builder"
-	"github.com/openshift/origin/tools/junitreport/pkg/builder/flat"
-	"github.com/openshift/origin/tools/junitreport/pkg/builder/nested"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/incremental"
 	"github.com/openshift/origin/tools/junitreport/pkg/parser"
 	"github.com/openshift/origin/tools/junitreport/pkg/parser/gotest"
-	"github.com/openshift/origin/tools/junitreport/pkg/parser/oscmd"
+
+	// the built-in test suites builders and parsers register themselves when they are imported
+	_ "github.com/openshift/origin/tools/junitreport/pkg/builder/flat"
+	_ "github.com/openshift/origin/tools/junitreport/pkg/builder/nested"
+	_ "github.com/openshift/origin/tools/junitreport/pkg/parser/gojson"
+	_ "github.com/openshift/origin/tools/junitreport/pkg/parser/oscmd"
+	_ "github.com/openshift/origin/tools/junitreport/pkg/parser/regex"
+	_ "github.com/openshift/origin/tools/junitreport/pkg/parser/tap"
 )
 
+// testSuitesBuilderType is the name under which a test suites builder is registered
 type testSuitesBuilderType string
 
-const (
-	flatBuilderType   testSuitesBuilderType = "flat"
-	nestedBuilderType testSuitesBuilderType = "nested"
-)
-
-var supportedBuilderTypes = []testSuitesBuilderType{flatBuilderType, nestedBuilderType}
+const flatBuilderType testSuitesBuilderType = "flat"
 
+// testParserType is the name under which a test output parser is registered
 type testParserType string
 
 const (
 	goTestParserType testParserType = "gotest"
-	osCmdParserType  testParserType = "oscmd"
+	goJSONParserType testParserType = "gojson"
 )
 
-var supportedTestParserTypes = []testParserType{goTestParserType, osCmdParserType}
-
 type JUnitReportOptions struct {
 	// BuilderType is the type of test suites builder to use
 	BuilderType testSuitesBuilderType
@@ -44,33 +46,75 @@ type JUnitReportOptions struct {
 	// ParserType is the parser type that will be used to parse test output
 	ParserType testParserType
 
+	// ParserConfig is the path to the configuration of the parser, for parser types that need to be configured
+	ParserConfig string
+
+	// NestSubtests determines if `go test` subtests should be nested in test suites for their parent tests
+	NestSubtests bool
+
 	// Stream determines if package result lines should be printed to the output as they are found
 	Stream bool
 
-	// Input is the reader for the test output to be parsed
+	// Incremental determines if test suites should be written to the output as soon as they are complete,
+	// instead of once all of the input has been parsed
+	Incremental bool
+
+	// Filter selects the test cases to report and redacts and truncates their output, if set
+	Filter *OutputFilter
+
+	// NamespaceSuites determines if the names of the test suites parsed from every input are prefixed with the
+	// name of the input file, so that suites with the same name in different inputs are kept apart
+	NamespaceSuites bool
+
+	// Input is the reader for the test output to be parsed, if Inputs is not set
 	Input io.Reader
 
+	// Inputs are the test outputs to be parsed, in order, into the same collection of test suites
+	Inputs []TestOutput
+
 	// Output is the writer for the file to which the XML is written
 	Output io.Writer
+
+	// lock guards the incremental builder, which may be closed while the input is parsed
+	lock sync.Mutex
+
+	// incrementalBuilder is the builder writing test suites to the output in incremental mode
+	incrementalBuilder *incremental.IncrementalTestSuitesBuilder
+
+	// newParser is the factory for the configured parser
+	newParser parser.Factory
 }
 
 func (o *JUnitReportOptions) Complete(builderType, parserType string, rootSuiteNames []string) error {
-	switch testSuitesBuilderType(builderType) {
-	case flatBuilderType:
-		o.BuilderType = flatBuilderType
-	case nestedBuilderType:
-		o.BuilderType = nestedBuilderType
-	default:
-		return fmt.Errorf("unrecognized test suites builder type: got %s, expected one of %v", builderType, supportedBuilderTypes)
+	if _, registered := builder.Lookup(builderType); !registered {
+		return fmt.Errorf("unrecognized test suites builder type: got %s, expected one of %v", builderType, builder.Names())
+	}
+	o.BuilderType = testSuitesBuilderType(builderType)
+
+	newParser, err := parser.Configure(parserType, o.ParserConfig)
+	if err != nil {
+		return err
 	}
+	o.ParserType = testParserType(parserType)
+	o.newParser = newParser
 
-	switch testParserType(parserType) {
-	case goTestParserType:
-		o.ParserType = goTestParserType
-	case osCmdParserType:
-		o.ParserType = osCmdParserType
-	default:
-		return fmt.Errorf("unrecognized test parser type: got %s, expected one of %v", parserType, supportedTestParserTypes)
+	if o.NestSubtests && o.ParserType != goTestParserType && o.ParserType != goJSONParserType {
+		return fmt.Errorf("nesting subtests is only supported for test parser types %v, got %s", []testParserType{goTestParserType, goJSONParserType}, o.ParserType)
+	}
+
+	if o.Incremental && o.BuilderType != flatBuilderType {
+		return fmt.Errorf("writing test suites incrementally is only supported for test suites builder type %s, got %s", flatBuilderType, o.BuilderType)
+	}
+
+	if o.NamespaceSuites {
+		namespaces := map[string]string{}
+		for _, input := range o.Inputs {
+			namespace := suiteNamespace(input.Name)
+			if other, exists := namespaces[namespace]; exists {
+				return fmt.Errorf("input files %s and %s have the same test suite namespace %q", other, input.Name, namespace)
+			}
+			namespaces[namespace] = input.Name
+		}
 	}
 
 	o.RootSuiteNames = rootSuiteNames
@@ -79,43 +123,124 @@ func (o *JUnitReportOptions) Complete(builderType, parserType string, rootSuiteN
 }
 
 func (o *JUnitReportOptions) Run() error {
-	var builder builder.TestSuitesBuilder
-	switch o.BuilderType {
-	case flatBuilderType:
-		builder = flat.NewTestSuitesBuilder()
-	case nestedBuilderType:
-		builder = nested.NewTestSuitesBuilder(o.RootSuiteNames)
+	newBuilder, registered := builder.Lookup(string(o.BuilderType))
+	if !registered {
+		return fmt.Errorf("unrecognized test suites builder type: %s", o.BuilderType)
+	}
+	newParser := o.newParser
+	if newParser == nil {
+		var err error
+		if newParser, err = parser.Configure(string(o.ParserType), o.ParserConfig); err != nil {
+			return err
+		}
 	}
 
-	var testParser parser.TestOutputParser
-	switch o.ParserType {
-	case goTestParserType:
-		testParser = gotest.NewParser(builder, o.Stream)
-	case osCmdParserType:
-		testParser = oscmd.NewParser(builder, o.Stream)
+	testSuitesBuilder := newBuilder(o.RootSuiteNames)
+	if o.Incremental {
+		o.lock.Lock()
+		o.incrementalBuilder = incremental.NewTestSuitesBuilder(o.Output)
+		o.lock.Unlock()
+		testSuitesBuilder = o.incrementalBuilder
+		if o.Filter != nil {
+			testSuitesBuilder = &filteringBuilder{TestSuitesBuilder: testSuitesBuilder, filter: o.Filter}
+		}
+		if o.NestSubtests {
+			testSuitesBuilder = &subtestNestingBuilder{TestSuitesBuilder: testSuitesBuilder}
+		}
 	}
 
-	testSuites, err := testParser.Parse(bufio.NewScanner(o.Input))
+	inputs := o.Inputs
+	if len(inputs) == 0 {
+		inputs = []TestOutput{{Reader: o.Input}}
+	}
+	// test suites with the same name in different inputs are merged, unless namespaces keep them apart. The flat
+	// builder keeps every test suite it is given, so they only need to be merged for other builders.
+	var merger *mergingBuilder
+	if len(inputs) > 1 && !o.NamespaceSuites && o.BuilderType != flatBuilderType {
+		merger = newMergingBuilder(testSuitesBuilder)
+		testSuitesBuilder = merger
+	}
+	var err error
+	for i, input := range inputs {
+		if merger != nil {
+			merger.input = i
+		}
+		// every input is parsed by a new parser, as parsers keep the state of the input they are reading
+		var inputBuilder builder.TestSuitesBuilder = &sharedBuilder{TestSuitesBuilder: testSuitesBuilder}
+		if o.NamespaceSuites {
+			inputBuilder = &namespacingBuilder{TestSuitesBuilder: inputBuilder, namespace: suiteNamespace(input.Name)}
+		}
+		testParser := newParser(inputBuilder, o.Stream)
+		if _, err = testParser.Parse(parser.NewScanner(input.Reader)); err != nil {
+			if len(input.Name) > 0 {
+				err = fmt.Errorf("error parsing %s: %v", input.Name, err)
+			}
+			break
+		}
+	}
+	testSuites := testSuitesBuilder.Build()
+	if o.Incremental {
+		// the suites completed before any parsing error have been written, so the document is closed regardless
+		if closeErr := o.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
 	if err != nil {
 		return err
 	}
 
-	_, err = io.WriteString(o.Output, xml.Header)
+	if o.NestSubtests {
+		gotest.NestSubtests(testSuites)
+	}
+	if o.Filter != nil {
+		o.Filter.Apply(testSuites)
+	}
+
+	return writeTestSuites(o.Output, testSuites)
+}
+
+// writeTestSuites writes the test suites to the output as an indented jUnit XML document
+func writeTestSuites(output io.Writer, testSuites *api.TestSuites) error {
+	_, err := io.WriteString(output, xml.Header)
 	if err != nil {
 		return fmt.Errorf("error writing XML header to file: %v", err)
 	}
 
-	encoder := xml.NewEncoder(o.Output)
+	encoder := xml.NewEncoder(output)
 	encoder.Indent("", "\t") // no prefix, indent with tabs
 
 	if err := encoder.Encode(testSuites); err != nil {
 		return fmt.Errorf("error encoding test suites to XML: %v", err)
 	}
 
-	_, err = io.WriteString(o.Output, "\n")
+	_, err = io.WriteString(output, "\n")
 	if err != nil {
 		return fmt.Errorf("error writing last newline to file: %v", err)
 	}
 
 	return nil
 }
+
+// Close closes the XML document when test suites are written incrementally, so that the output is well-formed
+// even if the input has not been parsed completely, e.g. when the process is interrupted. Test suites that are
+// completed after the document has been closed are not written.
+func (o *JUnitReportOptions) Close() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.incrementalBuilder == nil {
+		return nil
+	}
+	return o.incrementalBuilder.Close()
+}
+
+// subtestNestingBuilder nests `go test` subtests in every test suite before handing it to the underlying builder
+type subtestNestingBuilder struct {
+	builder.TestSuitesBuilder
+}
+
+func (b *subtestNestingBuilder) AddSuite(suite *api.TestSuite) {
+	gotest.NestSubtests(&api.TestSuites{Suites: []*api.TestSuite{suite}})
+	b.TestSuitesBuilder.AddSuite(suite)
+}
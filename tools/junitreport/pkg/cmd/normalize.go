@@ -0,0 +1,24 @@
+package cmd
+
+import "io"
+
+type NormalizeOptions struct {
+	// Input is the reader for the jUnit XML to be normalized
+	Input io.Reader
+
+	// Output is the writer for the normalized jUnit XML
+	Output io.Writer
+}
+
+// Run recomputes the metrics of all test suites in the jUnit XML from their test cases, sorts test suites and test
+// cases by name and writes the result
+func (o *NormalizeOptions) Run() error {
+	testSuites, err := decodeTestSuites(o.Input)
+	if err != nil {
+		return err
+	}
+
+	testSuites.Normalize()
+
+	return writeTestSuites(o.Output, testSuites)
+}
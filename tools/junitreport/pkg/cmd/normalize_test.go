@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+func TestNormalizeOptionsRun(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		input          string
+		expectedOutput string
+	}{
+		{
+			name: "inconsistent nested suites",
+			input: `<testsuites>
+	<testsuite name="package/other" tests="0" failures="0" time="0">
+		<testcase name="TestOne" time="0.5"></testcase>
+	</testsuite>
+	<testsuite name="package" tests="1" skipped="0" failures="0" time="10">
+		<testsuite name="package/name" tests="7" skipped="2" failures="0" time="0.1">
+			<testcase name="TestTwo" time="0.2"><skipped message="not today"></skipped></testcase>
+			<testcase name="TestOne" time="0.1"><failure message="boom">output</failure></testcase>
+			<testcase name="TestThree" time="0.3"><error message="panic"></error></testcase>
+		</testsuite>
+		<testsuite name="package/fast" tests="1" skipped="0" failures="0" time="0.2">
+			<testcase name="TestFlaky" time="0.1"><flakyFailure message="timed out"></flakyFailure></testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>`,
+			expectedOutput: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite name="package" tests="4" skipped="1" failures="1" errors="1" flakes="1" time="10">
+		<testsuite name="package/fast" tests="1" skipped="0" failures="0" flakes="1" time="0.2">
+			<testcase name="TestFlaky" time="0.1">
+				<flakyFailure message="timed out"></flakyFailure>
+			</testcase>
+		</testsuite>
+		<testsuite name="package/name" tests="3" skipped="1" failures="1" errors="1" time="0.6">
+			<testcase name="TestOne" time="0.1">
+				<failure message="boom">output</failure>
+			</testcase>
+			<testcase name="TestThree" time="0.3">
+				<error message="panic"></error>
+			</testcase>
+			<testcase name="TestTwo" time="0.2">
+				<skipped message="not today"></skipped>
+			</testcase>
+		</testsuite>
+	</testsuite>
+	<testsuite name="package/other" tests="1" skipped="0" failures="0" time="0.5">
+		<testcase name="TestOne" time="0.5"></testcase>
+	</testsuite>
+</testsuites>
+`,
+		},
+		{
+			name: "single suite",
+			input: `<testsuite name="package" tests="5" failures="0" time="0">
+	<testcase name="TestOne" time="0.1"></testcase>
+</testsuite>`,
+			expectedOutput: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite name="package" tests="1" skipped="0" failures="0" time="0.1">
+		<testcase name="TestOne" time="0.1"></testcase>
+	</testsuite>
+</testsuites>
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var output strings.Builder
+			options := NormalizeOptions{
+				Input:  strings.NewReader(testCase.input),
+				Output: &output,
+			}
+			if err := options.Run(); err != nil {
+				t.Fatalf("unexpected error normalizing: %v", err)
+			}
+			if output.String() != testCase.expectedOutput {
+				t.Errorf("did not normalize the jUnit XML correctly:\n%s", diff.ObjectReflectDiff(testCase.expectedOutput, output.String()))
+			}
+
+			// normalized jUnit XML is valid and does not change when it is normalized again
+			violations, err := Validate(strings.NewReader(output.String()))
+			if err != nil || len(violations) > 0 {
+				t.Errorf("expected normalized jUnit XML to be valid, got %v, %v", violations, err)
+			}
+			var again strings.Builder
+			options = NormalizeOptions{
+				Input:  strings.NewReader(output.String()),
+				Output: &again,
+			}
+			if err := options.Run(); err != nil {
+				t.Fatalf("unexpected error normalizing again: %v", err)
+			}
+			if again.String() != output.String() {
+				t.Errorf("normalizing again changed the jUnit XML:\n%s", diff.ObjectReflectDiff(output.String(), again.String()))
+			}
+		})
+	}
+}
+
+func TestNormalizeOptionsRunRejectsOtherRoots(t *testing.T) {
+	options := NormalizeOptions{
+		Input:  strings.NewReader(`<report></report>`),
+		Output: &strings.Builder{},
+	}
+	if err := options.Run(); err == nil {
+		t.Errorf("expected an error for an unexpected root element")
+	}
+}
@@ -2,18 +2,71 @@ package cmd
 
 import (
 	"bytes"
-	"encoding/xml"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/openshift/origin/tools/junitreport/pkg/api"
 )
 
+type summaryFormat string
+
+const (
+	textSummaryFormat     summaryFormat = "text"
+	jsonSummaryFormat     summaryFormat = "json"
+	markdownSummaryFormat summaryFormat = "markdown"
+)
+
+var supportedSummaryFormats = []summaryFormat{textSummaryFormat, jsonSummaryFormat, markdownSummaryFormat}
+
+type SummarizeOptions struct {
+	// Format is the format in which the summary is written
+	Format summaryFormat
+
+	// Input is the reader for the jUnit XML to be summarized
+	Input io.Reader
+
+	// Output is the writer for the summary
+	Output io.Writer
+}
+
+func (o *SummarizeOptions) Complete(format string) error {
+	switch summaryFormat(format) {
+	case textSummaryFormat, jsonSummaryFormat, markdownSummaryFormat:
+		o.Format = summaryFormat(format)
+	default:
+		return fmt.Errorf("unrecognized summary format: got %s, expected one of %v", format, supportedSummaryFormats)
+	}
+
+	return nil
+}
+
+func (o *SummarizeOptions) Run() error {
+	var summary string
+	var err error
+	switch o.Format {
+	case jsonSummaryFormat:
+		summary, err = SummarizeJSON(o.Input)
+	case markdownSummaryFormat:
+		summary, err = SummarizeMarkdown(o.Input)
+	default:
+		summary, err = Summarize(o.Input)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(o.Output, summary)
+	return err
+}
+
 // Summarize reads the input into a TestSuites structure and summarizes the tests contained within,
 // bringing attention to tests that did not succeed.
 func Summarize(input io.Reader) (string, error) {
-	var testSuites api.TestSuites
-	if err := xml.NewDecoder(input).Decode(&testSuites); err != nil {
+	testSuites, err := decodeTestSuites(input)
+	if err != nil {
 		return "", err
 	}
 
@@ -22,7 +75,7 @@ func Summarize(input io.Reader) (string, error) {
 	var duration float64
 	for _, testSuite := range testSuites.Suites {
 		numTests += testSuite.NumTests
-		numFailed += testSuite.NumFailed
+		numFailed += testSuite.NumFailed + testSuite.NumErrored
 		numSkipped += testSuite.NumSkipped
 		duration += testSuite.Duration
 	}
@@ -45,6 +98,9 @@ func summarizeTests(testSuite *api.TestSuite, summary *bytes.Buffer) {
 		if testCase.FailureOutput != nil {
 			summary.WriteString(fmt.Sprintf("In suite %q, test case %q failed:\n%s\n\n", testSuite.Name, testCase.Name, testCase.FailureOutput.Output))
 		}
+		if testCase.ErrorOutput != nil {
+			summary.WriteString(fmt.Sprintf("In suite %q, test case %q errored:\n%s\n\n", testSuite.Name, testCase.Name, testCase.ErrorOutput.Output))
+		}
 		if testCase.SkipMessage != nil {
 			summary.WriteString(fmt.Sprintf("In suite %q, test case %q was skipped:\n%s\n\n", testSuite.Name, testCase.Name, testCase.SkipMessage.Message))
 		}
@@ -54,3 +110,198 @@ func summarizeTests(testSuite *api.TestSuite, summary *bytes.Buffer) {
 		summarizeTests(childSuite, summary)
 	}
 }
+
+// Summary is the machine-readable summary of the tests in a jUnit XML file
+type Summary struct {
+	// NumTests is the number of tests executed
+	NumTests uint `json:"tests"`
+	// NumSucceeded is the number of tests that succeeded
+	NumSucceeded uint `json:"succeeded"`
+	// NumFailed is the number of tests that failed
+	NumFailed uint `json:"failed"`
+	// NumSkipped is the number of tests that were skipped
+	NumSkipped uint `json:"skipped"`
+	// Duration is the time taken in seconds to run all tests
+	Duration float64 `json:"duration"`
+	// Failures holds the test cases that failed
+	Failures []TestCaseSummary `json:"failures"`
+	// Skips holds the test cases that were skipped
+	Skips []TestCaseSummary `json:"skips"`
+}
+
+// TestCaseSummary describes a test case that did not succeed
+type TestCaseSummary struct {
+	// Suite is the name of the suite holding the test case
+	Suite string `json:"suite"`
+	// SuitePath holds the names of the suites from the root suite down to the suite holding the test case
+	SuitePath []string `json:"suitePath"`
+	// Name is the name of the test case
+	Name string `json:"name"`
+	// Duration is the time taken in seconds to run the test case
+	Duration float64 `json:"duration"`
+	// Message is the failure or skip message of the test case
+	Message string `json:"message,omitempty"`
+	// Output is the failure output of the test case
+	Output string `json:"output,omitempty"`
+}
+
+// NewSummary summarizes the test suites, collecting the tests that did not succeed in the order they appear in
+func NewSummary(testSuites *api.TestSuites) *Summary {
+	summary := &Summary{
+		Failures: []TestCaseSummary{},
+		Skips:    []TestCaseSummary{},
+	}
+	for _, testSuite := range testSuites.Suites {
+		summary.NumTests += testSuite.NumTests
+		// tests that errored did not succeed either, and are summarized as failures
+		summary.NumFailed += testSuite.NumFailed + testSuite.NumErrored
+		summary.NumSkipped += testSuite.NumSkipped
+		summary.Duration += testSuite.Duration
+		collectTestCases(testSuite, nil, summary)
+	}
+	summary.NumSucceeded = summary.NumTests - summary.NumFailed - summary.NumSkipped
+	// we round to the millisecond on duration
+	summary.Duration = float64(int(summary.Duration*1000)) / 1000
+	return summary
+}
+
+func collectTestCases(testSuite *api.TestSuite, parentPath []string, summary *Summary) {
+	path := append(append([]string{}, parentPath...), testSuite.Name)
+	for _, testCase := range testSuite.TestCases {
+		caseSummary := TestCaseSummary{
+			Suite:     testSuite.Name,
+			SuitePath: path,
+			Name:      testCase.Name,
+			Duration:  testCase.Duration,
+		}
+		switch {
+		case testCase.FailureOutput != nil:
+			caseSummary.Message = testCase.FailureOutput.Message
+			caseSummary.Output = testCase.FailureOutput.Output
+			summary.Failures = append(summary.Failures, caseSummary)
+		case testCase.ErrorOutput != nil:
+			caseSummary.Message = testCase.ErrorOutput.Message
+			caseSummary.Output = testCase.ErrorOutput.Output
+			summary.Failures = append(summary.Failures, caseSummary)
+		case testCase.SkipMessage != nil:
+			caseSummary.Message = testCase.SkipMessage.Message
+			summary.Skips = append(summary.Skips, caseSummary)
+		}
+	}
+
+	for _, childSuite := range testSuite.Children {
+		collectTestCases(childSuite, path, summary)
+	}
+}
+
+// SummarizeJSON reads the input into a TestSuites structure and summarizes the tests contained within as JSON
+func SummarizeJSON(input io.Reader) (string, error) {
+	testSuites, err := decodeTestSuites(input)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(NewSummary(testSuites), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding summary to JSON: %v", err)
+	}
+	return string(data) + "\n", nil
+}
+
+const (
+	// maxMarkdownOutputLength is the maximum length of the failure output shown for a single test case
+	maxMarkdownOutputLength = 4000
+
+	// maxMarkdownLength is the length after which no more failure output is added to a Markdown summary, so that
+	// the summary fits in a GitHub comment, which is limited to 65536 characters
+	maxMarkdownLength = 60000
+
+	// maxMarkdownTableRows is the maximum number of test cases listed in the table of tests that did not succeed
+	maxMarkdownTableRows = 100
+)
+
+// SummarizeMarkdown reads the input into a TestSuites structure and summarizes the tests contained within as
+// Markdown, with a table of the tests that did not succeed and collapsible failure output
+func SummarizeMarkdown(input io.Reader) (string, error) {
+	testSuites, err := decodeTestSuites(input)
+	if err != nil {
+		return "", err
+	}
+	summary := NewSummary(testSuites)
+
+	var markdown bytes.Buffer
+	markdown.WriteString("| Tests | Succeeded | Failed | Skipped | Duration |\n")
+	markdown.WriteString("| ---: | ---: | ---: | ---: | ---: |\n")
+	markdown.WriteString(fmt.Sprintf("| %d | %d | %d | %d | %.3fs |\n", summary.NumTests, summary.NumSucceeded, summary.NumFailed, summary.NumSkipped, summary.Duration))
+
+	if len(summary.Failures) > 0 || len(summary.Skips) > 0 {
+		markdown.WriteString("\n| Result | Suite | Test case | Duration |\n")
+		markdown.WriteString("| --- | --- | --- | ---: |\n")
+		rows := 0
+		for _, testCase := range summary.Failures {
+			if rows == maxMarkdownTableRows {
+				break
+			}
+			markdown.WriteString(fmt.Sprintf("| failed | %s | %s | %.3fs |\n", markdownCode(testCase.Suite), markdownCode(testCase.Name), testCase.Duration))
+			rows++
+		}
+		for _, testCase := range summary.Skips {
+			if rows == maxMarkdownTableRows {
+				break
+			}
+			markdown.WriteString(fmt.Sprintf("| skipped | %s | %s | %.3fs |\n", markdownCode(testCase.Suite), markdownCode(testCase.Name), testCase.Duration))
+			rows++
+		}
+		if omitted := len(summary.Failures) + len(summary.Skips) - rows; omitted > 0 {
+			markdown.WriteString(fmt.Sprintf("\n... and %d more\n", omitted))
+		}
+	}
+
+	for i, testCase := range summary.Failures {
+		details := markdownDetails(testCase)
+		if markdown.Len()+len(details) > maxMarkdownLength {
+			markdown.WriteString(fmt.Sprintf("\nOutput for %d more failed test cases was omitted.\n", len(summary.Failures)-i))
+			break
+		}
+		markdown.WriteString(details)
+	}
+
+	return markdown.String(), nil
+}
+
+// markdownDetails renders the failure output of a test case in a collapsible section
+func markdownDetails(testCase TestCaseSummary) string {
+	output := testCase.Output
+	if len(output) > maxMarkdownOutputLength {
+		// the output is not cut in the middle of a multi-byte character
+		end := maxMarkdownOutputLength
+		for end > 0 && !utf8.RuneStart(output[end]) {
+			end--
+		}
+		output = output[:end] + "\n... (truncated)"
+	}
+	if len(testCase.Message) > 0 {
+		output = testCase.Message + "\n" + output
+	}
+
+	// the fence must be longer than any run of backticks in the output for the output to be rendered verbatim
+	fence := "```"
+	for strings.Contains(output, fence) {
+		fence += "`"
+	}
+
+	return fmt.Sprintf("\n<details>\n<summary>%s %s failed</summary>\n\n%s\n%s\n%s\n\n</details>\n", markdownCode(testCase.Suite), markdownCode(testCase.Name), fence, output, fence)
+}
+
+// markdownCode renders the text as inline code that is safe to use in a table cell
+func markdownCode(text string) string {
+	text = strings.Replace(text, "|", `\|`, -1)
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " " + text + " " + fence
+	}
+	return fence + text + fence
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+const summaryTestXML = `<testsuites>
+	<testsuite name="package" tests="3" skipped="1" failures="1" time="0.3">
+		<testcase name="TestPass" time="0.1"></testcase>
+		<testcase name="TestSkip" time="0"><skipped message="not today"></skipped></testcase>
+		<testsuite name="package/child" tests="1" skipped="0" failures="1" time="0.2">
+			<testcase name="TestFail|pipe" time="0.2"><failure message="boom">some ` + "```" + ` output</failure></testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>`
+
+func TestSummarizeJSON(t *testing.T) {
+	output, err := SummarizeJSON(strings.NewReader(summaryTestXML))
+	if err != nil {
+		t.Fatalf("unexpected error summarizing: %v", err)
+	}
+
+	var summary Summary
+	if err := json.Unmarshal([]byte(output), &summary); err != nil {
+		t.Fatalf("unexpected error decoding summary: %v", err)
+	}
+
+	expected := Summary{
+		NumTests:     3,
+		NumSucceeded: 1,
+		NumFailed:    1,
+		NumSkipped:   1,
+		Duration:     0.3,
+		Failures: []TestCaseSummary{
+			{
+				Suite:     "package/child",
+				SuitePath: []string{"package", "package/child"},
+				Name:      "TestFail|pipe",
+				Duration:  0.2,
+				Message:   "boom",
+				Output:    "some ``` output",
+			},
+		},
+		Skips: []TestCaseSummary{
+			{
+				Suite:     "package",
+				SuitePath: []string{"package"},
+				Name:      "TestSkip",
+				Message:   "not today",
+			},
+		},
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("did not produce the correct summary:\n%s", diff.ObjectReflectDiff(expected, summary))
+	}
+}
+
+func TestSummarizeMarkdown(t *testing.T) {
+	output, err := SummarizeMarkdown(strings.NewReader(summaryTestXML))
+	if err != nil {
+		t.Fatalf("unexpected error summarizing: %v", err)
+	}
+
+	expected := "| Tests | Succeeded | Failed | Skipped | Duration |\n" +
+		"| ---: | ---: | ---: | ---: | ---: |\n" +
+		"| 3 | 1 | 1 | 1 | 0.300s |\n" +
+		"\n" +
+		"| Result | Suite | Test case | Duration |\n" +
+		"| --- | --- | --- | ---: |\n" +
+		"| failed | `package/child` | `TestFail\\|pipe` | 0.200s |\n" +
+		"| skipped | `package` | `TestSkip` | 0.000s |\n" +
+		"\n" +
+		"<details>\n" +
+		"<summary>`package/child` `TestFail\\|pipe` failed</summary>\n" +
+		"\n" +
+		"````\n" +
+		"boom\n" +
+		"some ``` output\n" +
+		"````\n" +
+		"\n" +
+		"</details>\n"
+	if output != expected {
+		t.Errorf("did not produce the correct summary:\n%s", diff.ObjectReflectDiff(expected, output))
+	}
+}
+
+func TestSummarizeMarkdownLimitsLength(t *testing.T) {
+	var xml strings.Builder
+	xml.WriteString(`<testsuites><testsuite name="package" tests="100" failures="100" time="0">`)
+	for i := 0; i < 100; i++ {
+		xml.WriteString(`<testcase name="TestFail" time="0"><failure message="">`)
+		xml.WriteString(strings.Repeat("x", 2*maxMarkdownOutputLength))
+		xml.WriteString(`</failure></testcase>`)
+	}
+	xml.WriteString(`</testsuite></testsuites>`)
+
+	output, err := SummarizeMarkdown(strings.NewReader(xml.String()))
+	if err != nil {
+		t.Fatalf("unexpected error summarizing: %v", err)
+	}
+	if len(output) > maxMarkdownLength+100 {
+		t.Errorf("expected summary to be limited to about %d characters, got %d", maxMarkdownLength, len(output))
+	}
+	if !strings.Contains(output, "... (truncated)") {
+		t.Errorf("expected failure output to be truncated")
+	}
+	if !strings.HasSuffix(output, "more failed test cases was omitted.\n") {
+		t.Errorf("expected omitted failure output to be noted")
+	}
+}
+
+func TestSummarizeMarkdownLimitsTableRows(t *testing.T) {
+	var xml strings.Builder
+	xml.WriteString(`<testsuites><testsuite name="package" tests="150" skipped="50" failures="100" time="0">`)
+	for i := 0; i < 100; i++ {
+		xml.WriteString(`<testcase name="TestFail" time="0"><failure message=""></failure></testcase>`)
+	}
+	for i := 0; i < 50; i++ {
+		xml.WriteString(`<testcase name="TestSkip" time="0"><skipped message=""></skipped></testcase>`)
+	}
+	xml.WriteString(`</testsuite></testsuites>`)
+
+	output, err := SummarizeMarkdown(strings.NewReader(xml.String()))
+	if err != nil {
+		t.Fatalf("unexpected error summarizing: %v", err)
+	}
+	if rows := strings.Count(output, "| failed |") + strings.Count(output, "| skipped |"); rows != maxMarkdownTableRows {
+		t.Errorf("expected %d table rows, got %d", maxMarkdownTableRows, rows)
+	}
+	if !strings.Contains(output, "\n... and 50 more\n") {
+		t.Errorf("expected omitted table rows to be noted")
+	}
+}
+
+func TestMarkdownDetailsTruncatesOnRuneBoundary(t *testing.T) {
+	output := "x" + strings.Repeat("é", maxMarkdownOutputLength)
+	details := markdownDetails(TestCaseSummary{Suite: "package", Name: "TestFail", Output: output})
+	if !utf8.ValidString(details) {
+		t.Errorf("expected truncated failure output to be valid UTF-8")
+	}
+	if !strings.Contains(details, "é\n... (truncated)") {
+		t.Errorf("expected failure output to be truncated")
+	}
+}
+
+func TestSummarizeJSONCountsErrorsAsFailures(t *testing.T) {
+	output, err := SummarizeJSON(strings.NewReader(`<testsuites>
+	<testsuite name="package" tests="1" skipped="0" failures="0" errors="1" time="0.1">
+		<testcase name="TestError" time="0.1" file="package/file_test.go" line="12"><error message="panic" type="runtime.Error">stack</error></testcase>
+	</testsuite>
+</testsuites>`))
+	if err != nil {
+		t.Fatalf("unexpected error summarizing: %v", err)
+	}
+
+	var summary Summary
+	if err := json.Unmarshal([]byte(output), &summary); err != nil {
+		t.Fatalf("unexpected error decoding summary: %v", err)
+	}
+
+	expected := Summary{
+		NumTests:  1,
+		NumFailed: 1,
+		Duration:  0.1,
+		Failures: []TestCaseSummary{
+			{
+				Suite:     "package",
+				SuitePath: []string{"package"},
+				Name:      "TestError",
+				Duration:  0.1,
+				Message:   "panic",
+				Output:    "stack",
+			},
+		},
+		Skips: []TestCaseSummary{},
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("did not produce the correct summary:\n%s", diff.ObjectReflectDiff(expected, summary))
+	}
+}
+
+func TestSummarizeSingleSuite(t *testing.T) {
+	input := `<testsuite name="package" tests="2" skipped="0" failures="1" time="0.3">
+	<testcase name="TestPass" time="0.1"></testcase>
+	<testcase name="TestFail" time="0.2"><failure message="boom">output</failure></testcase>
+</testsuite>`
+
+	var testCases = []struct {
+		name      string
+		summarize func(io.Reader) (string, error)
+		expected  string
+	}{
+		{
+			name:      "text",
+			summarize: Summarize,
+			expected:  "Of 2 tests executed in 0.300s, 1 succeeded, 1 failed, and 0 were skipped.",
+		},
+		{
+			name:      "json",
+			summarize: SummarizeJSON,
+			expected:  `"failed": 1`,
+		},
+		{
+			name:      "markdown",
+			summarize: SummarizeMarkdown,
+			expected:  "| failed | `package` | `TestFail` | 0.200s |",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			output, err := testCase.summarize(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("unexpected error summarizing: %v", err)
+			}
+			if !strings.Contains(output, testCase.expected) {
+				t.Errorf("expected summary to contain %q, got:\n%s", testCase.expected, output)
+			}
+		})
+	}
+}
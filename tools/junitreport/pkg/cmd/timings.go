@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+type TimingsOptions struct {
+	// Format is the format in which the timing report is written
+	Format summaryFormat
+
+	// NumSlowest is the number of test cases and test suites listed as the slowest ones
+	NumSlowest int
+
+	// Input is the reader for the jUnit XML to be analyzed
+	Input io.Reader
+
+	// Output is the writer for the timing report
+	Output io.Writer
+}
+
+func (o *TimingsOptions) Complete(format string, numSlowest int) error {
+	switch summaryFormat(format) {
+	case textSummaryFormat, jsonSummaryFormat:
+		o.Format = summaryFormat(format)
+	default:
+		return fmt.Errorf("unrecognized timing report format: got %s, expected one of %v", format, []summaryFormat{textSummaryFormat, jsonSummaryFormat})
+	}
+
+	if numSlowest < 0 {
+		return fmt.Errorf("invalid number of slowest tests %d: expected a number that is not negative", numSlowest)
+	}
+	o.NumSlowest = numSlowest
+
+	return nil
+}
+
+func (o *TimingsOptions) Run() error {
+	testSuites, err := decodeTestSuites(o.Input)
+	if err != nil {
+		return err
+	}
+
+	report := NewTimingReport(testSuites, o.NumSlowest)
+
+	var output string
+	switch o.Format {
+	case jsonSummaryFormat:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding timing report to JSON: %v", err)
+		}
+		output = string(data) + "\n"
+	default:
+		output = report.String()
+	}
+	_, err = io.WriteString(o.Output, output)
+	return err
+}
+
+// TimingReport describes where the time to run the tests in a jUnit XML file was spent
+type TimingReport struct {
+	// Duration is the total time in seconds taken by all test suites
+	Duration float64 `json:"duration"`
+	// SlowestTests holds the slowest test cases, slowest first
+	SlowestTests []TestCaseTiming `json:"slowestTests"`
+	// SlowestSuites holds the slowest test suites that hold test cases themselves, slowest first. Suites that only
+	// hold other suites are left out, as they would always be slower than the suites they hold.
+	SlowestSuites []SuiteTiming `json:"slowestSuites"`
+	// Suites holds the timing of every suite, in depth-first order
+	Suites []SuiteTiming `json:"suites"`
+}
+
+// TestCaseTiming is the duration of a test case
+type TestCaseTiming struct {
+	// Suite is the name of the suite holding the test case
+	Suite string `json:"suite"`
+	// Name is the name of the test case
+	Name string `json:"name"`
+	// Duration is the time taken in seconds to run the test case
+	Duration float64 `json:"duration"`
+}
+
+// SuiteTiming is the duration of a test suite and the distribution of the durations of its test cases
+type SuiteTiming struct {
+	// Name is the name of the suite
+	Name string `json:"name"`
+	// Depth is the number of suites the suite is nested under
+	Depth int `json:"depth"`
+	// Duration is the time taken in seconds to run the suite, including the suites nested under it
+	Duration float64 `json:"duration"`
+	// Share is the percentage of the total duration taken by the suite, including the suites nested under it
+	Share float64 `json:"share"`
+	// NumTests is the number of test cases in the suite and the suites nested under it
+	NumTests int `json:"tests"`
+	// Distribution describes the durations of the test cases in the suite and the suites nested under it
+	Distribution DurationDistribution `json:"distribution"`
+}
+
+// DurationDistribution describes the distribution of the durations of test cases, in seconds
+type DurationDistribution struct {
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	P90    float64 `json:"p90"`
+	P99    float64 `json:"p99"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+}
+
+// NewTimingReport analyzes the durations of all test suites and test cases. Test suites may be nested or flat.
+func NewTimingReport(testSuites *api.TestSuites, numSlowest int) *TimingReport {
+	report := &TimingReport{
+		SlowestTests:  []TestCaseTiming{},
+		SlowestSuites: []SuiteTiming{},
+		Suites:        []SuiteTiming{},
+	}
+	for _, suite := range testSuites.Suites {
+		report.Duration += suite.Duration
+	}
+	report.Duration = roundDuration(report.Duration)
+
+	var testCases []TestCaseTiming
+	var leafSuites []SuiteTiming
+	for _, suite := range testSuites.Suites {
+		collectTimings(suite, 0, report, &testCases, &leafSuites)
+	}
+
+	sort.SliceStable(testCases, func(i, j int) bool {
+		return testCases[i].Duration > testCases[j].Duration
+	})
+	if len(testCases) > numSlowest {
+		testCases = testCases[:numSlowest]
+	}
+	report.SlowestTests = append(report.SlowestTests, testCases...)
+
+	sort.SliceStable(leafSuites, func(i, j int) bool {
+		return leafSuites[i].Duration > leafSuites[j].Duration
+	})
+	if len(leafSuites) > numSlowest {
+		leafSuites = leafSuites[:numSlowest]
+	}
+	report.SlowestSuites = append(report.SlowestSuites, leafSuites...)
+
+	return report
+}
+
+// collectTimings records the timing of a suite and the suites nested under it in the report, collecting the timings
+// of all test cases and of the suites holding test cases, and returns the durations of the test cases in the suite
+func collectTimings(suite *api.TestSuite, depth int, report *TimingReport, testCases *[]TestCaseTiming, leafSuites *[]SuiteTiming) []float64 {
+	// the suite is recorded before the suites nested under it, once the durations of its test cases are known
+	index := len(report.Suites)
+	report.Suites = append(report.Suites, SuiteTiming{
+		Name:     suite.Name,
+		Depth:    depth,
+		Duration: roundDuration(suite.Duration),
+	})
+	if report.Duration > 0 {
+		report.Suites[index].Share = roundPercentage(suite.Duration / report.Duration * 100)
+	}
+
+	var durations []float64
+	for _, testCase := range suite.TestCases {
+		durations = append(durations, testCase.Duration)
+		*testCases = append(*testCases, TestCaseTiming{Suite: suite.Name, Name: testCase.Name, Duration: testCase.Duration})
+	}
+	holdsTestCases := len(durations) > 0
+
+	for _, child := range suite.Children {
+		durations = append(durations, collectTimings(child, depth+1, report, testCases, leafSuites)...)
+	}
+
+	report.Suites[index].NumTests = len(durations)
+	report.Suites[index].Distribution = newDurationDistribution(durations)
+	if holdsTestCases {
+		*leafSuites = append(*leafSuites, report.Suites[index])
+	}
+	return durations
+}
+
+// newDurationDistribution describes the distribution of the durations, using the nearest-rank method for percentiles
+func newDurationDistribution(durations []float64) DurationDistribution {
+	if len(durations) == 0 {
+		return DurationDistribution{}
+	}
+
+	sorted := append([]float64{}, durations...)
+	sort.Float64s(sorted)
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+		return roundDuration(sorted[rank-1])
+	}
+
+	var sum float64
+	for _, duration := range sorted {
+		sum += duration
+	}
+	return DurationDistribution{
+		Min:    roundDuration(sorted[0]),
+		Median: percentile(50),
+		P90:    percentile(90),
+		P99:    percentile(99),
+		Max:    roundDuration(sorted[len(sorted)-1]),
+		Mean:   roundDuration(sum / float64(len(sorted))),
+	}
+}
+
+// roundDuration rounds a duration in seconds to the millisecond
+func roundDuration(duration float64) float64 {
+	return math.Round(duration*1000) / 1000
+}
+
+// String formats the timing report as tables of the slowest test cases and test suites, followed by a table of all
+// test suites with the share of the total duration they took and the distribution of the durations of their tests
+func (r *TimingReport) String() string {
+	var report bytes.Buffer
+	if len(r.Suites) == 0 {
+		report.WriteString("No test suites were recorded.\n")
+		return report.String()
+	}
+
+	report.WriteString(fmt.Sprintf("Test suites took %.3fs in total.\n", r.Duration))
+
+	if len(r.SlowestTests) > 0 {
+		report.WriteString("\nSlowest test cases:\n")
+		writer := tabwriter.NewWriter(&report, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(writer, "SUITE\tTEST CASE\tDURATION")
+		for _, testCase := range r.SlowestTests {
+			fmt.Fprintf(writer, "%s\t%s\t%.3fs\n", testCase.Suite, testCase.Name, testCase.Duration)
+		}
+		writer.Flush()
+	}
+
+	if len(r.SlowestSuites) > 0 {
+		report.WriteString("\nSlowest test suites:\n")
+		writer := tabwriter.NewWriter(&report, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(writer, "SUITE\tDURATION\tSHARE\tTESTS")
+		for _, suite := range r.SlowestSuites {
+			fmt.Fprintf(writer, "%s\t%.3fs\t%.1f%%\t%d\n", suite.Name, suite.Duration, suite.Share, suite.NumTests)
+		}
+		writer.Flush()
+	}
+
+	report.WriteString("\nTest suites:\n")
+	writer := tabwriter.NewWriter(&report, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "SUITE\tDURATION\tSHARE\tTESTS\tMIN\tMEDIAN\tP90\tP99\tMAX\tMEAN")
+	for _, suite := range r.Suites {
+		// suites are indented below the suites they are nested under
+		name := strings.Repeat("  ", suite.Depth) + suite.Name
+		if suite.NumTests == 0 {
+			fmt.Fprintf(writer, "%s\t%.3fs\t%.1f%%\t0\t-\t-\t-\t-\t-\t-\n", name, suite.Duration, suite.Share)
+			continue
+		}
+		distribution := suite.Distribution
+		fmt.Fprintf(writer, "%s\t%.3fs\t%.1f%%\t%d\t%.3fs\t%.3fs\t%.3fs\t%.3fs\t%.3fs\t%.3fs\n", name, suite.Duration, suite.Share, suite.NumTests,
+			distribution.Min, distribution.Median, distribution.P90, distribution.P99, distribution.Max, distribution.Mean)
+	}
+	writer.Flush()
+
+	return report.String()
+}
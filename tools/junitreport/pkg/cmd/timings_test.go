@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+func TestNewTimingReport(t *testing.T) {
+	testSuites := &api.TestSuites{
+		Suites: []*api.TestSuite{
+			{
+				Name:     "root",
+				Duration: 8,
+				Children: []*api.TestSuite{
+					{
+						Name:     "root/slow",
+						Duration: 6,
+						TestCases: []*api.TestCase{
+							{Name: "TestA", Duration: 1},
+							{Name: "TestB", Duration: 2},
+							{Name: "TestC", Duration: 3},
+						},
+					},
+					{
+						Name:     "root/fast",
+						Duration: 2,
+						TestCases: []*api.TestCase{
+							{Name: "TestD", Duration: 0.5},
+							{Name: "TestE", Duration: 1.5},
+						},
+					},
+				},
+			},
+			{
+				Name:     "empty",
+				Duration: 2,
+			},
+		},
+	}
+
+	expected := &TimingReport{
+		Duration: 10,
+		SlowestTests: []TestCaseTiming{
+			{Suite: "root/slow", Name: "TestC", Duration: 3},
+			{Suite: "root/slow", Name: "TestB", Duration: 2},
+		},
+		SlowestSuites: []SuiteTiming{
+			{Name: "root/slow", Depth: 1, Duration: 6, Share: 60, NumTests: 3, Distribution: DurationDistribution{Min: 1, Median: 2, P90: 3, P99: 3, Max: 3, Mean: 2}},
+			{Name: "root/fast", Depth: 1, Duration: 2, Share: 20, NumTests: 2, Distribution: DurationDistribution{Min: 0.5, Median: 0.5, P90: 1.5, P99: 1.5, Max: 1.5, Mean: 1}},
+		},
+		Suites: []SuiteTiming{
+			{Name: "root", Duration: 8, Share: 80, NumTests: 5, Distribution: DurationDistribution{Min: 0.5, Median: 1.5, P90: 3, P99: 3, Max: 3, Mean: 1.6}},
+			{Name: "root/slow", Depth: 1, Duration: 6, Share: 60, NumTests: 3, Distribution: DurationDistribution{Min: 1, Median: 2, P90: 3, P99: 3, Max: 3, Mean: 2}},
+			{Name: "root/fast", Depth: 1, Duration: 2, Share: 20, NumTests: 2, Distribution: DurationDistribution{Min: 0.5, Median: 0.5, P90: 1.5, P99: 1.5, Max: 1.5, Mean: 1}},
+			{Name: "empty", Duration: 2, Share: 20},
+		},
+	}
+
+	report := NewTimingReport(testSuites, 2)
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("did not produce the correct timing report:\n%s", diff.ObjectReflectDiff(expected, report))
+	}
+
+	expectedText := `Test suites took 10.000s in total.
+
+Slowest test cases:
+SUITE      TEST CASE  DURATION
+root/slow  TestC      3.000s
+root/slow  TestB      2.000s
+
+Slowest test suites:
+SUITE      DURATION  SHARE  TESTS
+root/slow  6.000s    60.0%  3
+root/fast  2.000s    20.0%  2
+
+Test suites:
+SUITE        DURATION  SHARE  TESTS  MIN     MEDIAN  P90     P99     MAX     MEAN
+root         8.000s    80.0%  5      0.500s  1.500s  3.000s  3.000s  3.000s  1.600s
+  root/slow  6.000s    60.0%  3      1.000s  2.000s  3.000s  3.000s  3.000s  2.000s
+  root/fast  2.000s    20.0%  2      0.500s  0.500s  1.500s  1.500s  1.500s  1.000s
+empty        2.000s    20.0%  0      -       -       -       -       -       -
+`
+	if text := report.String(); text != expectedText {
+		t.Errorf("did not format the timing report correctly:\n%s", diff.ObjectReflectDiff(expectedText, text))
+	}
+}
+
+func TestTimingsOptionsComplete(t *testing.T) {
+	options := TimingsOptions{}
+	if err := options.Complete("markdown", 10); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+	if err := options.Complete("json", -1); err == nil {
+		t.Errorf("expected an error for a negative number of slowest tests")
+	}
+	if err := options.Complete("json", 10); err != nil {
+		t.Errorf("unexpected error completing options: %v", err)
+	}
+}
+
+func TestTimingsOptionsRunSingleSuite(t *testing.T) {
+	var output strings.Builder
+	options := TimingsOptions{
+		Input: strings.NewReader(`<testsuite name="package" tests="2" skipped="0" failures="0" time="0.3">
+	<testcase name="TestFast" time="0.1"></testcase>
+	<testcase name="TestSlow" time="0.2"></testcase>
+</testsuite>`),
+		Output: &output,
+	}
+	if err := options.Complete("json", 1); err != nil {
+		t.Fatalf("unexpected error completing options: %v", err)
+	}
+	if err := options.Run(); err != nil {
+		t.Fatalf("unexpected error reporting timings: %v", err)
+	}
+
+	var report TimingReport
+	if err := json.Unmarshal([]byte(output.String()), &report); err != nil {
+		t.Fatalf("unexpected error decoding timing report: %v", err)
+	}
+	if report.Duration != 0.3 || len(report.SlowestTests) != 1 || report.SlowestTests[0].Name != "TestSlow" {
+		t.Errorf("did not report the timings of the single suite: %s", output.String())
+	}
+}
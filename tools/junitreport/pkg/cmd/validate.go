@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type ValidateOptions struct {
+	// Format is the format in which violations are written
+	Format summaryFormat
+
+	// Input is the reader for the jUnit XML to be validated
+	Input io.Reader
+
+	// Output is the writer for the violations
+	Output io.Writer
+}
+
+func (o *ValidateOptions) Complete(format string) error {
+	switch summaryFormat(format) {
+	case textSummaryFormat, jsonSummaryFormat:
+		o.Format = summaryFormat(format)
+	default:
+		return fmt.Errorf("unrecognized validation report format: got %s, expected one of %v", format, []summaryFormat{textSummaryFormat, jsonSummaryFormat})
+	}
+
+	return nil
+}
+
+// Run writes the violations found in the jUnit XML and returns an error if there are any
+func (o *ValidateOptions) Run() error {
+	violations, err := Validate(o.Input)
+	if err != nil {
+		return err
+	}
+
+	var output string
+	switch o.Format {
+	case jsonSummaryFormat:
+		data, err := json.MarshalIndent(violations, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding violations to JSON: %v", err)
+		}
+		output = string(data) + "\n"
+	default:
+		var report bytes.Buffer
+		for _, violation := range violations {
+			report.WriteString(violation.String() + "\n")
+		}
+		if len(violations) == 0 {
+			report.WriteString("The jUnit XML file is valid.\n")
+		}
+		output = report.String()
+	}
+	if _, err := io.WriteString(o.Output, output); err != nil {
+		return err
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("found %d violation(s)", len(violations))
+	}
+	return nil
+}
+
+// Violation is a rule of the jUnit XML format that an element breaks
+type Violation struct {
+	// Path locates the element, like `/testsuites/testsuite[@name="package"]/testcase[2]`. Elements are identified
+	// by their name attribute if they have one, or by their position among the siblings of the same type otherwise.
+	Path string `json:"path"`
+	// Message describes the violation
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+var (
+	// allowedChildren are the elements allowed in every element of a jUnit XML file. This follows the schema in the
+	// api package, extended by the elements that common consumers like Jenkins and the Maven Surefire plugin
+	// understand and that the api package writes, like nested test suites and properties outside of a
+	// `properties` element.
+	allowedChildren = map[string][]string{
+		"testsuites":   {"testsuite"},
+		"testsuite":    {"properties", "property", "testcase", "testsuite", "system-out", "system-err"},
+		"properties":   {"property"},
+		"property":     {},
+		"testcase":     {"properties", "skipped", "failure", "error", "rerunFailure", "rerunError", "flakyFailure", "flakyError", "system-out", "system-err"},
+		"skipped":      {},
+		"failure":      {},
+		"error":        {},
+		"rerunFailure": {"stackTrace", "system-out", "system-err"},
+		"rerunError":   {"stackTrace", "system-out", "system-err"},
+		"flakyFailure": {"stackTrace", "system-out", "system-err"},
+		"flakyError":   {"stackTrace", "system-out", "system-err"},
+		"stackTrace":   {},
+		"system-out":   {},
+		"system-err":   {},
+	}
+
+	// suiteCountAttributes are the attributes of test suites counting test cases, in the order they are reported
+	suiteCountAttributes = []string{"tests", "failures", "errors", "skipped", "flakes"}
+
+	// timestampPattern matches the timestamps allowed by the schema, optionally with fractional seconds and a time zone
+	timestampPattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})?$`)
+)
+
+// validationFrame holds the state of an element whose children are being validated
+type validationFrame struct {
+	name string
+	path string
+
+	// siblings counts the children of the element by name, to locate the children without a name attribute
+	siblings map[string]int
+
+	// counts holds the number of test cases of every kind recomputed for a test suite, keyed by the attribute
+	// recording them
+	counts map[string]int
+
+	// declared holds the counts recorded in the attributes of a test suite
+	declared map[string]int
+
+	// results holds the result elements of a test case, of which there may be at most one
+	results []string
+
+	// flaky records that a test case has flaky attempts
+	flaky bool
+}
+
+// Validate checks that the jUnit XML is structurally valid and that the counts recorded for test suites match the
+// test cases they hold, returning every violation found. An error is only returned if the input is not well-formed.
+func Validate(input io.Reader) ([]Violation, error) {
+	violations := []Violation{}
+	report := func(path, format string, args ...interface{}) {
+		violations = append(violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	decoder := xml.NewDecoder(input)
+	var stack []*validationFrame
+	var root bool
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing jUnit XML: %v", err)
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			name := element.Name.Local
+			attributes := map[string]string{}
+			for _, attribute := range element.Attr {
+				attributes[attribute.Name.Local] = attribute.Value
+			}
+
+			var parent *validationFrame
+			parentPath := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+				parentPath = parent.path
+			}
+			frame := &validationFrame{
+				name:     name,
+				path:     parentPath + "/" + elementSegment(name, attributes, parent),
+				siblings: map[string]int{},
+			}
+
+			switch {
+			case parent == nil && root:
+				report(frame.path, "unexpected element after the root element")
+				decoder.Skip()
+				continue
+			case parent == nil && name != "testsuites" && name != "testsuite":
+				report(frame.path, "unexpected root element %q, expected %q or %q", name, "testsuites", "testsuite")
+				decoder.Skip()
+				continue
+			case parent != nil && !contains(allowedChildren[parent.name], name):
+				report(frame.path, "unexpected element %q in %q, expected one of %v", name, parent.name, allowedChildren[parent.name])
+				decoder.Skip()
+				continue
+			}
+			root = true
+
+			switch name {
+			case "testsuites":
+				frame.counts = map[string]int{}
+				frame.declared = validateCountAttributes(frame.path, attributes, false, report)
+				validateDurationAttribute(frame.path, attributes, false, report)
+			case "testsuite":
+				frame.counts = map[string]int{}
+				validateNameAttribute(frame.path, attributes, report)
+				frame.declared = validateCountAttributes(frame.path, attributes, true, report)
+				validateDurationAttribute(frame.path, attributes, false, report)
+				if timestamp, ok := attributes["timestamp"]; ok && !timestampPattern.MatchString(timestamp) {
+					report(frame.path, "invalid timestamp %q, expected an ISO 8601 date and time like %q", timestamp, "2006-01-02T15:04:05")
+				}
+			case "testcase":
+				validateNameAttribute(frame.path, attributes, report)
+				validateDurationAttribute(frame.path, attributes, true, report)
+				for _, attribute := range []string{"line", "assertions"} {
+					if value, ok := attributes[attribute]; ok {
+						if number, err := strconv.Atoi(value); err != nil || number < 0 {
+							report(frame.path, "invalid %s attribute %q, expected a number that is not negative", attribute, value)
+						}
+					}
+				}
+			case "property":
+				validateNameAttribute(frame.path, attributes, report)
+				if _, ok := attributes["value"]; !ok {
+					report(frame.path, "missing value attribute")
+				}
+			case "skipped", "failure", "error":
+				parent.results = append(parent.results, name)
+			case "flakyFailure", "flakyError":
+				parent.flaky = true
+			}
+			stack = append(stack, frame)
+
+		case xml.EndElement:
+			frame := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			var parent *validationFrame
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+
+			switch frame.name {
+			case "testcase":
+				if len(frame.results) > 1 {
+					report(frame.path, "test case has more than one result: %s", strings.Join(frame.results, ", "))
+				}
+				// the first result determines how a test case is counted, like when it is added to a test suite
+				parent.counts["tests"] += 1
+				if len(frame.results) > 0 {
+					switch frame.results[0] {
+					case "skipped":
+						parent.counts["skipped"] += 1
+					case "failure":
+						parent.counts["failures"] += 1
+					case "error":
+						parent.counts["errors"] += 1
+					}
+				}
+				if frame.flaky {
+					parent.counts["flakes"] += 1
+				}
+			case "testsuite", "testsuites":
+				for _, attribute := range suiteCountAttributes {
+					if declared, ok := frame.declared[attribute]; ok && declared != frame.counts[attribute] {
+						report(frame.path, "%s attribute is %d, but %d were counted", attribute, declared, frame.counts[attribute])
+					}
+				}
+				if parent != nil {
+					// the counts of a suite include those of the suites nested under it
+					for attribute, count := range frame.counts {
+						parent.counts[attribute] += count
+					}
+				}
+			}
+		}
+	}
+
+	if !root {
+		report("/", "no root element, expected %q or %q", "testsuites", "testsuite")
+	}
+	return violations, nil
+}
+
+// elementSegment locates an element among its siblings, by its name attribute if it has one
+func elementSegment(name string, attributes map[string]string, parent *validationFrame) string {
+	if len(attributes["name"]) > 0 {
+		return fmt.Sprintf("%s[@name=%q]", name, attributes["name"])
+	}
+	if parent == nil {
+		return name
+	}
+	parent.siblings[name] += 1
+	return fmt.Sprintf("%s[%d]", name, parent.siblings[name])
+}
+
+// validateNameAttribute checks that an element has a name
+func validateNameAttribute(path string, attributes map[string]string, report func(string, string, ...interface{})) {
+	if len(strings.TrimSpace(attributes["name"])) == 0 {
+		report(path, "missing or empty name attribute")
+	}
+}
+
+// validateCountAttributes checks the attributes counting the test cases in a test suite and returns their values.
+// `tests` and `failures` are required of test suites, while the other counts may be left out when they are zero.
+func validateCountAttributes(path string, attributes map[string]string, suite bool, report func(string, string, ...interface{})) map[string]int {
+	declared := map[string]int{}
+	for _, attribute := range suiteCountAttributes {
+		value, ok := attributes[attribute]
+		if !ok {
+			if suite && (attribute == "tests" || attribute == "failures") {
+				report(path, "missing %s attribute", attribute)
+			} else if suite {
+				declared[attribute] = 0
+			}
+			continue
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			report(path, "invalid %s attribute %q, expected a number that is not negative", attribute, value)
+			continue
+		}
+		declared[attribute] = count
+	}
+	return declared
+}
+
+// validateDurationAttribute checks that the duration of an element is a number of seconds, if it is recorded
+func validateDurationAttribute(path string, attributes map[string]string, required bool, report func(string, string, ...interface{})) {
+	value, ok := attributes["time"]
+	if !ok {
+		if required {
+			report(path, "missing time attribute")
+		}
+		return
+	}
+	if duration, err := strconv.ParseFloat(value, 64); err != nil || duration < 0 {
+		report(path, "invalid time attribute %q, expected a number of seconds that is not negative", value)
+	}
+}
+
+// contains determines if the list holds the value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
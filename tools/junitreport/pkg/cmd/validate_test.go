@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+func TestValidate(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		input              string
+		expectedViolations []Violation
+	}{
+		{
+			name: "valid nested suites",
+			input: `<testsuites>
+	<testsuite name="package" tests="4" skipped="1" failures="1" errors="1" flakes="1" time="1.5" timestamp="2018-05-10T10:00:00Z">
+		<property name="coverage.statements.pct" value="50.0"></property>
+		<testcase name="TestSkipped" time="0"><skipped message="not today"></skipped></testcase>
+		<testsuite name="package/child" tests="3" failures="1" errors="1" flakes="1" time="1.5">
+			<testcase name="TestFails" time="0.5" file="child_test.go" line="10"><failure message="boom">output</failure><system-out>out</system-out></testcase>
+			<testcase name="TestErrors" time="0.5"><error message="panic"></error></testcase>
+			<testcase name="TestFlaky" time="0.5"><flakyFailure message="timed out"><stackTrace>trace</stackTrace></flakyFailure></testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>`,
+			expectedViolations: []Violation{},
+		},
+		{
+			name:               "single suite",
+			input:              `<testsuite name="package" tests="1" failures="0"><testcase name="TestOne" time="0"></testcase></testsuite>`,
+			expectedViolations: []Violation{},
+		},
+		{
+			name: "inconsistent counts",
+			input: `<testsuites tests="3">
+	<testsuite name="package" tests="3" skipped="0" failures="0" time="1">
+		<testcase name="TestFails" time="0"><failure message="boom"></failure></testcase>
+		<testsuite name="package/child" tests="1" skipped="1" failures="0" time="1">
+			<testcase name="TestPasses" time="0"></testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>`,
+			expectedViolations: []Violation{
+				{Path: `/testsuites/testsuite[@name="package"]/testsuite[@name="package/child"]`, Message: "skipped attribute is 1, but 0 were counted"},
+				{Path: `/testsuites/testsuite[@name="package"]`, Message: "tests attribute is 3, but 2 were counted"},
+				{Path: `/testsuites/testsuite[@name="package"]`, Message: "failures attribute is 0, but 1 were counted"},
+				{Path: `/testsuites`, Message: "tests attribute is 3, but 2 were counted"},
+			},
+		},
+		{
+			name: "structural violations",
+			input: `<testsuites>
+	<testsuite name="" tests="many" time="-1" timestamp="yesterday">
+		<testcase time="fast" line="-3"><skipped></skipped><failure message="boom"></failure></testcase>
+		<testcase name="TestOne"><bogus></bogus></testcase>
+		<property value="value"></property>
+		<property name="name"></property>
+	</testsuite>
+</testsuites>`,
+			expectedViolations: []Violation{
+				{Path: `/testsuites/testsuite[1]`, Message: "missing or empty name attribute"},
+				{Path: `/testsuites/testsuite[1]`, Message: `invalid tests attribute "many", expected a number that is not negative`},
+				{Path: `/testsuites/testsuite[1]`, Message: "missing failures attribute"},
+				{Path: `/testsuites/testsuite[1]`, Message: `invalid time attribute "-1", expected a number of seconds that is not negative`},
+				{Path: `/testsuites/testsuite[1]`, Message: `invalid timestamp "yesterday", expected an ISO 8601 date and time like "2006-01-02T15:04:05"`},
+				{Path: `/testsuites/testsuite[1]/testcase[1]`, Message: "missing or empty name attribute"},
+				{Path: `/testsuites/testsuite[1]/testcase[1]`, Message: `invalid time attribute "fast", expected a number of seconds that is not negative`},
+				{Path: `/testsuites/testsuite[1]/testcase[1]`, Message: `invalid line attribute "-3", expected a number that is not negative`},
+				{Path: `/testsuites/testsuite[1]/testcase[1]`, Message: "test case has more than one result: skipped, failure"},
+				{Path: `/testsuites/testsuite[1]/testcase[@name="TestOne"]`, Message: "missing time attribute"},
+				{Path: `/testsuites/testsuite[1]/testcase[@name="TestOne"]/bogus[1]`, Message: `unexpected element "bogus" in "testcase", expected one of [properties skipped failure error rerunFailure rerunError flakyFailure flakyError system-out system-err]`},
+				{Path: `/testsuites/testsuite[1]/property[1]`, Message: "missing or empty name attribute"},
+				{Path: `/testsuites/testsuite[1]/property[@name="name"]`, Message: "missing value attribute"},
+				{Path: `/testsuites/testsuite[1]`, Message: "skipped attribute is 0, but 1 were counted"},
+			},
+		},
+		{
+			name:  "unexpected root",
+			input: `<report></report>`,
+			expectedViolations: []Violation{
+				{Path: `/report`, Message: `unexpected root element "report", expected "testsuites" or "testsuite"`},
+				{Path: `/`, Message: `no root element, expected "testsuites" or "testsuite"`},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			violations, err := Validate(strings.NewReader(testCase.input))
+			if err != nil {
+				t.Fatalf("unexpected error validating: %v", err)
+			}
+			if !reflect.DeepEqual(violations, testCase.expectedViolations) {
+				t.Errorf("did not find the correct violations:\n%s", diff.ObjectReflectDiff(testCase.expectedViolations, violations))
+			}
+		})
+	}
+}
+
+func TestValidateOptionsRun(t *testing.T) {
+	var output strings.Builder
+	options := ValidateOptions{
+		Input:  strings.NewReader(`<testsuites><testsuite name="package" tests="1" failures="0" time="0"></testsuite></testsuites>`),
+		Output: &output,
+	}
+	if err := options.Complete("text"); err != nil {
+		t.Fatalf("unexpected error completing options: %v", err)
+	}
+	err := options.Run()
+	if err == nil || err.Error() != "found 1 violation(s)" {
+		t.Errorf("expected an error for the violation, got %v", err)
+	}
+
+	expected := "/testsuites/testsuite[@name=\"package\"]: tests attribute is 1, but 0 were counted\n"
+	if output.String() != expected {
+		t.Errorf("did not report the violations correctly:\n%s", diff.ObjectReflectDiff(expected, output.String()))
+	}
+
+	options.Input = strings.NewReader(`<testsuites><testsuite name="package"`)
+	if err := options.Run(); err == nil {
+		t.Errorf("expected an error for XML that is not well-formed")
+	}
+}
@@ -0,0 +1,93 @@
+package gojson
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a single record in the stream emitted by `go test -json`, as documented by `go doc test2json`
+type Event struct {
+	// Time is the time at which the event occurred
+	Time time.Time
+	// Action is the type of event, one of start, run, pause, cont, pass, bench, fail, output, skip, build-output
+	// or build-fail
+	Action string
+	// Package is the import path of the package being tested
+	Package string
+	// Test is the name of the test the event belongs to, if any
+	Test string
+	// Elapsed is the time in seconds taken by the test or package on a pass, fail or skip event
+	Elapsed float64
+	// Output is the text printed by the test or package on an output or build-output event
+	Output string
+	// ImportPath is the package being built on a build-output or build-fail event
+	ImportPath string
+	// FailedBuild is the package that failed to build on the fail event of a package that could not be tested
+	FailedBuild string
+}
+
+// Actions that can be recorded in an Event
+const (
+	actionStart  = "start"
+	actionRun    = "run"
+	actionPause  = "pause"
+	actionCont   = "cont"
+	actionPass   = "pass"
+	actionBench  = "bench"
+	actionFail   = "fail"
+	actionOutput = "output"
+	actionSkip   = "skip"
+
+	actionBuildOutput = "build-output"
+	actionBuildFail   = "build-fail"
+)
+
+// ExtractEvent decodes a line of `go test -json` output into an Event. Lines that are not JSON objects, like
+// the ones written by `go test` itself when a package fails to build, are not events.
+func ExtractEvent(line string) (*Event, bool) {
+	if !strings.HasPrefix(line, "{") {
+		return nil, false
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		return nil, false
+	}
+
+	if len(event.Action) == 0 {
+		return nil, false
+	}
+	return &event, true
+}
+
+// framingPattern matches the lines that `go test -v` prints to delimit test execution, which are
+// already recorded in the actions of the events and are not useful as test output
+var framingPattern = regexp.MustCompile(`^\s*(=== (RUN|PAUSE|CONT|NAME)\s|--- (PASS|FAIL|SKIP|BENCH):\s)`)
+
+// ExtractOutput returns the output of an output event with leading indentation and the trailing newline removed,
+// and whether the output is useful to record for the test, i.e. that it is not a framing line.
+func ExtractOutput(event *Event) (string, bool) {
+	if event.Action != actionOutput {
+		return "", false
+	}
+
+	return ExtractOutputLine(event.Output)
+}
+
+// ExtractOutputLine returns a line of output with leading indentation and the trailing newline removed, and whether
+// the output is useful to record for the test, i.e. that it is not a framing line.
+func ExtractOutputLine(line string) (string, bool) {
+	output := strings.TrimRight(line, "\n")
+	if framingPattern.MatchString(output) {
+		return "", false
+	}
+	return strings.TrimLeft(output, " \t"), true
+}
+
+// ExtractDuration formats the elapsed time of an event as a duration that can be parsed by time.ParseDuration
+func ExtractDuration(event *Event) string {
+	return strconv.FormatFloat(event.Elapsed, 'f', -1, 64) + "s"
+}
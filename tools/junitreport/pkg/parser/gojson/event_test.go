@@ -0,0 +1,134 @@
+package gojson
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExtractEvent(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		testLine      string
+		expectedEvent *Event
+		expectedOk    bool
+	}{
+		{
+			name:     "test output",
+			testLine: `{"Time":"2018-01-01T00:00:00Z","Action":"output","Package":"package/name","Test":"TestOne","Output":"=== RUN   TestOne\n"}`,
+			expectedEvent: &Event{
+				Time:    time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+				Action:  "output",
+				Package: "package/name",
+				Test:    "TestOne",
+				Output:  "=== RUN   TestOne\n",
+			},
+			expectedOk: true,
+		},
+		{
+			name:     "package result",
+			testLine: `{"Time":"2018-01-01T00:00:00Z","Action":"pass","Package":"package/name","Elapsed":0.16}`,
+			expectedEvent: &Event{
+				Time:    time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+				Action:  "pass",
+				Package: "package/name",
+				Elapsed: 0.16,
+			},
+			expectedOk: true,
+		},
+		{
+			name:     "build output",
+			testLine: "# package/name",
+		},
+		{
+			name:     "malformed",
+			testLine: `{"Action":"pass",`,
+		},
+		{
+			name:     "not an event",
+			testLine: `{"Package":"package/name"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		event, ok := ExtractEvent(testCase.testLine)
+		if ok != testCase.expectedOk {
+			t.Errorf("%s: did not correctly determine if line %q was an event: expected %t, got %t", testCase.name, testCase.testLine, testCase.expectedOk, ok)
+		}
+		if !reflect.DeepEqual(event, testCase.expectedEvent) {
+			t.Errorf("%s: did not correctly extract event from line %q: expected %#v, got %#v", testCase.name, testCase.testLine, testCase.expectedEvent, event)
+		}
+	}
+}
+
+func TestExtractOutput(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		event          *Event
+		expectedOutput string
+		expectedOk     bool
+	}{
+		{
+			name:           "indented output",
+			event:          &Event{Action: "output", Output: "    file_test.go:11: Error message\n"},
+			expectedOutput: "file_test.go:11: Error message",
+			expectedOk:     true,
+		},
+		{
+			name:  "run framing",
+			event: &Event{Action: "output", Output: "=== RUN   TestOne\n"},
+		},
+		{
+			name:  "continue framing",
+			event: &Event{Action: "output", Output: "=== CONT  TestOne\n"},
+		},
+		{
+			name:  "nested result framing",
+			event: &Event{Action: "output", Output: "    --- FAIL: TestOne/sub (0.00s)\n"},
+		},
+		{
+			name:  "not output",
+			event: &Event{Action: "pass"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		output, ok := ExtractOutput(testCase.event)
+		if ok != testCase.expectedOk {
+			t.Errorf("%s: did not correctly determine if event carried output: expected %t, got %t", testCase.name, testCase.expectedOk, ok)
+		}
+		if output != testCase.expectedOutput {
+			t.Errorf("%s: did not correctly extract output: expected %q, got %q", testCase.name, testCase.expectedOutput, output)
+		}
+	}
+}
+
+func TestExtractDuration(t *testing.T) {
+	var testCases = []struct {
+		name             string
+		elapsed          float64
+		expectedDuration string
+	}{
+		{
+			name:             "zero",
+			elapsed:          0,
+			expectedDuration: "0s",
+		},
+		{
+			name:             "fractional",
+			elapsed:          0.16,
+			expectedDuration: "0.16s",
+		},
+		{
+			name:             "long",
+			elapsed:          123.456,
+			expectedDuration: "123.456s",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if duration := ExtractDuration(&Event{Elapsed: testCase.elapsed}); duration != testCase.expectedDuration {
+			t.Errorf("%s: did not correctly extract duration: expected %q, got %q", testCase.name, testCase.expectedDuration, duration)
+		}
+	}
+}
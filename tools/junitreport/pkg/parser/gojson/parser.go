@@ -0,0 +1,305 @@
+package gojson
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser/gotest"
+)
+
+func init() {
+	parser.Register("gojson", "the output of 'go test -json'", NewParser)
+}
+
+// NewParser returns a new parser that's capable of parsing `go test -json` output
+func NewParser(builder builder.TestSuitesBuilder, stream bool) parser.TestOutputParser {
+	return &testOutputParser{
+		builder: builder,
+		stream:  stream,
+	}
+}
+
+type testOutputParser struct {
+	builder builder.TestSuitesBuilder
+	stream  bool
+
+	// buildOutput holds the compiler output for packages, keyed by the package name
+	buildOutput map[string][]string
+}
+
+// packageRecord holds the state of a package for which events are being received
+type packageRecord struct {
+	suite *api.TestSuite
+
+	// tests holds the records of all tests that have been started in the package, keyed by name
+	tests map[string]*testRecord
+
+	// orderedTests holds the names of the tests in the package in the order in which they were started
+	orderedTests []string
+
+	// output holds the output written by the package outside of any test, like the panic of a test binary that
+	// timed out, which is attributed to the tests that did not conclude
+	output []string
+
+	// lastOutput is the last line of output written by the package outside of any test
+	lastOutput string
+
+	// partialOutput holds the output of the package and its tests that did not end a line yet, keyed by test name
+	partialOutput map[string]string
+}
+
+// completeLine returns the line of output of an output event, joined with the output of previous events that did
+// not end the line, and whether the line is complete. `go test` writes the name of a benchmark before running it
+// and its results once it is done, so the results of a benchmark may be spread over several events. Complete
+// benchmark results are recorded in a test case named after the benchmark as reported, as `go test` does not
+// conclude benchmarks with events of their own, and are not considered output.
+func (r *packageRecord) completeLine(event *Event) (string, bool) {
+	r.partialOutput[event.Test] += event.Output
+	output := r.partialOutput[event.Test]
+	if !strings.HasSuffix(output, "\n") {
+		return "", false
+	}
+	delete(r.partialOutput, event.Test)
+
+	line := strings.TrimRight(output, "\n")
+	if benchmark, ok := gotest.ExtractBenchmark(line); ok {
+		test := r.test(benchmark.Name)
+		gotest.RecordBenchmark(test.testCase, benchmark)
+		test.benchmarked = true
+		return "", false
+	}
+	return line, true
+}
+
+// test returns the record of the test with the given name, starting a new record if the test was not seen before
+func (r *packageRecord) test(name string) *testRecord {
+	test, exists := r.tests[name]
+	if !exists {
+		test = &testRecord{
+			testCase: &api.TestCase{Name: name},
+		}
+		r.tests[name] = test
+		r.orderedTests = append(r.orderedTests, name)
+	}
+	return test
+}
+
+// testRecord holds the state of a test for which events are being received
+type testRecord struct {
+	testCase *api.TestCase
+	result   api.TestResult
+	output   []string
+
+	// benchmarked determines if the result of a benchmark was recorded for the test
+	benchmarked bool
+}
+
+// Parse parses `go test -json` output into test suites. Every event carries the package and test it belongs to, so
+// unlike the parser for `go test -v` output, interleaved output from parallel tests and packages is attributed to
+// the correct test case. A test suite is handed to the builder once the package it represents concludes.
+func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error) {
+	packages := map[string]*packageRecord{}
+	// orderedPackages holds the names of the packages in progress in the order in which they were started
+	var orderedPackages []string
+
+	p.buildOutput = map[string][]string{}
+	// buildPackage is the package whose compiler output is written by `go test` outside of events
+	var buildPackage string
+
+	for input.Scan() {
+		line := input.Text()
+		event, ok := ExtractEvent(line)
+		if !ok {
+			// before Go 1.24, compiler output and the failure of packages that could not be tested are not events
+			if name, reason, ok := gotest.ExtractPackageFailure(line); ok {
+				suite := &api.TestSuite{Name: name}
+				suite.AddTestCase(gotest.NewPackageFailureTestCase(reason, p.buildOutput[name]))
+				p.builder.AddSuite(suite)
+				buildPackage = ""
+			} else if name, ok := gotest.ExtractBuildOutputHeader(line); ok {
+				buildPackage = name
+			} else if len(buildPackage) > 0 {
+				p.buildOutput[buildPackage] = append(p.buildOutput[buildPackage], line)
+			}
+			continue
+		}
+
+		switch event.Action {
+		case actionBuildOutput:
+			output := strings.TrimRight(event.Output, "\n")
+			if _, ok := gotest.ExtractBuildOutputHeader(output); !ok {
+				name := packageName(event.ImportPath)
+				p.buildOutput[name] = append(p.buildOutput[name], output)
+			}
+			continue
+		case actionBuildFail:
+			continue
+		}
+
+		record, exists := packages[event.Package]
+		if !exists {
+			record = &packageRecord{
+				suite:         &api.TestSuite{Name: event.Package},
+				tests:         map[string]*testRecord{},
+				partialOutput: map[string]string{},
+			}
+			packages[event.Package] = record
+			orderedPackages = append(orderedPackages, event.Package)
+		}
+
+		if len(event.Test) == 0 {
+			switch event.Action {
+			case actionOutput:
+				output, complete := record.completeLine(event)
+				if !complete {
+					continue
+				}
+				if properties, ok := gotest.ExtractProperties(output); ok {
+					for name, value := range properties {
+						record.suite.AddProperty(name, value)
+					}
+				}
+				record.output = append(record.output, output)
+				record.lastOutput = output
+			case actionPass, actionFail:
+				if err := p.conclude(record, event); err != nil {
+					return nil, err
+				}
+				delete(packages, event.Package)
+			case actionSkip:
+				// packages without test files are skipped and have nothing to report
+				delete(packages, event.Package)
+			}
+			continue
+		}
+
+		test := record.test(event.Test)
+
+		switch event.Action {
+		case actionOutput:
+			line, complete := record.completeLine(event)
+			if !complete {
+				continue
+			}
+			if output, ok := ExtractOutputLine(line); ok && !(isBenchmark(event.Test) && output == event.Test) {
+				test.output = append(test.output, output)
+			}
+		case actionPass, actionFail, actionSkip:
+			test.result = api.TestResult(event.Action)
+			if err := test.testCase.SetDuration(ExtractDuration(event)); err != nil {
+				return nil, fmt.Errorf("unexpected duration for test %q: %v", event.Test, err)
+			}
+		}
+	}
+
+	// a line that cannot be read ends the scan early, which must not be mistaken for the end of the input
+	if err := input.Err(); err != nil {
+		return nil, fmt.Errorf("error reading test output: %v", err)
+	}
+
+	// if the input ends early, e.g. because a test binary was killed, the packages in progress are still reported
+	// and any test that did not conclude is considered failed
+	for _, name := range orderedPackages {
+		if record, exists := packages[name]; exists {
+			if err := p.conclude(record, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return p.builder.Build(), nil
+}
+
+// conclude finalizes all of the test cases in a package and adds the package's test suite to the builder. Tests
+// that never concluded, like those running when a test binary times out, are marked as failed. If the input ended
+// before the package concluded, there is no event concluding it.
+func (p *testOutputParser) conclude(record *packageRecord, event *Event) error {
+	for _, name := range record.orderedTests {
+		test := record.tests[name]
+		output := strings.Join(test.output, "\n")
+		switch {
+		case len(test.result) == 0 && test.benchmarked:
+			// a benchmark that reported its result succeeded unless it was concluded otherwise
+			test.testCase.SystemOut = output
+		case len(test.result) == 0 && isBenchmark(name) && event != nil:
+			// benchmarks without results of their own only group sub-benchmarks, which report the results
+			continue
+		case len(test.result) == 0:
+			test.testCase.MarkFailed(parser.IncompleteTestMessage, strings.Join(append(test.output, record.output...), "\n"))
+		case test.result == api.TestResultFail:
+			test.testCase.MarkFailed("", output)
+		case test.result == api.TestResultSkip:
+			test.testCase.MarkSkipped(output)
+		default:
+			test.testCase.SystemOut = output
+		}
+		record.suite.AddTestCase(test.testCase)
+	}
+
+	if event != nil && event.Action == actionFail && record.suite.NumFailed == 0 {
+		p.addPackageFailure(record, event)
+	}
+
+	if event == nil {
+		// the package never concluded, so the sum of test case durations is all we know of its duration
+		p.addSuite(record)
+		return nil
+	}
+
+	// the package duration is reported by `go test`, so it overrides the sum of test case durations
+	if err := record.suite.SetDuration(ExtractDuration(event)); err != nil {
+		return fmt.Errorf("unexpected duration for package %q: %v", event.Package, err)
+	}
+
+	if p.stream {
+		fmt.Fprintln(os.Stdout, record.lastOutput)
+	}
+
+	p.addSuite(record)
+	return nil
+}
+
+// addPackageFailure records the failure of a package that is not attributed to any test, like a build failure
+// or a panic before any test ran, as a failing test case holding the compiler output or the package output
+func (p *testOutputParser) addPackageFailure(record *packageRecord, event *Event) {
+	var reason string
+	for _, line := range record.output {
+		if _, packageReason, ok := gotest.ExtractPackageFailure(line); ok {
+			reason = packageReason
+		}
+	}
+
+	output := record.output
+	if buildOutput, ok := p.buildOutput[event.Package]; ok && (len(reason) > 0 || len(event.FailedBuild) > 0) {
+		output = buildOutput
+	}
+	record.suite.AddTestCase(gotest.NewPackageFailureTestCase(reason, output))
+}
+
+// isBenchmark determines if the test is a benchmark or one of its sub-benchmarks
+func isBenchmark(test string) bool {
+	return strings.HasPrefix(test, "Benchmark")
+}
+
+// packageName returns the name of the package from the import path of a build event, which may name the test
+// binary being built as well, like `package/name [package/name.test]`
+func packageName(importPath string) string {
+	if i := strings.Index(importPath, " "); i >= 0 {
+		return importPath[:i]
+	}
+	return importPath
+}
+
+// addSuite adds the test suite of a concluded package to the builder
+func (p *testOutputParser) addSuite(record *packageRecord) {
+	// packages without any tests, like those with `[no tests to run]`, are not reported
+	if len(record.suite.TestCases) == 0 {
+		return
+	}
+	p.builder.AddSuite(record.suite)
+}
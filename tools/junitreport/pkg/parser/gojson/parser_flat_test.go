@@ -0,0 +1,360 @@
+package gojson
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/flat"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser"
+)
+
+// TestFlatParse tests that parsing the `go test -json` output in the test directory with a flat builder works as expected
+func TestFlatParse(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		testFile       string
+		expectedSuites *api.TestSuites
+	}{
+		{
+			name:     "basic",
+			testFile: "1.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:     "package/name",
+						NumTests: 2,
+						Duration: 0.16,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestOne",
+								Duration: 0.06,
+							},
+							{
+								Name:     "TestTwo",
+								Duration: 0.1,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "failure, skip and coverage",
+			testFile: "2.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:       "package/name",
+						NumTests:   3,
+						NumSkipped: 1,
+						NumFailed:  1,
+						Duration:   0.15,
+						Properties: []*api.TestSuiteProperty{
+							{
+								Name:  "coverage.statements.pct",
+								Value: "13.37",
+							},
+						},
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestOne",
+								Duration: 0.02,
+								FailureOutput: &api.FailureOutput{
+									Output: "file_test.go:11: Error message\nfile_test.go:11: Longer\nerror\nmessage.",
+								},
+							},
+							{
+								Name:     "TestTwo",
+								Duration: 0.01,
+								SkipMessage: &api.SkipMessage{
+									Message: "file_test.go:12: Skip message",
+								},
+							},
+							{
+								Name:     "TestThree",
+								Duration: 0.12,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "interleaved parallel tests and packages",
+			testFile: "3.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:       "package/name/two",
+						NumTests:   3,
+						NumSkipped: 1,
+						Duration:   0.03,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestSubTests",
+								Duration: 0.02,
+							},
+							{
+								Name:     "TestSubTests/pass",
+								Duration: 0.02,
+							},
+							{
+								Name: "TestSubTests/skip",
+								SkipMessage: &api.SkipMessage{
+									Message: "file_test.go:30: Skip message",
+								},
+							},
+						},
+					},
+					{
+						Name:      "package/name/one",
+						NumTests:  2,
+						NumFailed: 1,
+						Duration:  0.06,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestOne",
+								Duration: 0.05,
+								FailureOutput: &api.FailureOutput{
+									Output: "file_test.go:10: Error message from one",
+								},
+							},
+							{
+								Name:     "TestTwo",
+								Duration: 0.04,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "timed out and truncated output",
+			testFile: "4.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  2,
+						NumFailed: 1,
+						Duration:  600.005,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestOne",
+								Duration: 0.06,
+							},
+							{
+								Name: "TestTwo",
+								FailureOutput: &api.FailureOutput{
+									Message: "test did not complete",
+									Output:  "two_test.go:10: waiting for the server\npanic: test timed out after 10m0s\nFAIL\tpackage/name\t600.005s",
+								},
+							},
+						},
+					},
+					{
+						Name:      "package/other",
+						NumTests:  1,
+						NumFailed: 1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "TestThree",
+								FailureOutput: &api.FailureOutput{
+									Message: "test did not complete",
+									Output:  "output before the test binary was killed",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "package failures outside of tests",
+			testFile: "5.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/broken",
+						NumTests:  1,
+						NumFailed: 1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "[build failed]",
+								FailureOutput: &api.FailureOutput{
+									Message: "build failed",
+									Output:  "broken/file_test.go:10:2: undefined: foo",
+								},
+							},
+						},
+					},
+					{
+						Name:      "package/other",
+						NumTests:  1,
+						NumFailed: 1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "[build failed]",
+								FailureOutput: &api.FailureOutput{
+									Message: "build failed",
+									Output:  "other/file_test.go:5:2: undefined: bar",
+								},
+							},
+						},
+					},
+					{
+						Name:      "package/panics",
+						NumTests:  1,
+						NumFailed: 1,
+						Duration:  0.005,
+						TestCases: []*api.TestCase{
+							{
+								Name: "[package failed]",
+								FailureOutput: &api.FailureOutput{
+									Message: "panic: cannot load configuration",
+									Output:  "panic: cannot load configuration\n\ngoroutine 1 [running]:\nFAIL\tpackage/panics\t0.005s",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "benchmarks",
+			testFile: "6.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:     "package/name",
+						NumTests: 5,
+						Duration: 0.005,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestA",
+								Duration: 0.005,
+							},
+							{
+								Name: "BenchmarkX",
+								Properties: &api.TestCaseProperties{
+									Properties: []*api.TestCaseProperty{
+										{Name: "iterations", Value: "100"},
+										{Name: "ns/op", Value: "12.50"},
+										{Name: "custom/op", Value: "3.000"},
+										{Name: "B/op", Value: "0"},
+										{Name: "allocs/op", Value: "0"},
+									},
+								},
+							},
+							{
+								Name: "BenchmarkX-4",
+								Properties: &api.TestCaseProperties{
+									Properties: []*api.TestCaseProperty{
+										{Name: "iterations", Value: "100"},
+										{Name: "GOMAXPROCS", Value: "4"},
+										{Name: "ns/op", Value: "12.50"},
+										{Name: "custom/op", Value: "3.000"},
+										{Name: "B/op", Value: "0"},
+										{Name: "allocs/op", Value: "0"},
+									},
+								},
+							},
+							{
+								Name: "BenchmarkY/sub",
+								Properties: &api.TestCaseProperties{
+									Properties: []*api.TestCaseProperty{
+										{Name: "iterations", Value: "100"},
+										{Name: "ns/op", Value: "12.50"},
+										{Name: "B/op", Value: "0"},
+										{Name: "allocs/op", Value: "0"},
+									},
+								},
+							},
+							{
+								Name: "BenchmarkY/sub-4",
+								Properties: &api.TestCaseProperties{
+									Properties: []*api.TestCaseProperty{
+										{Name: "iterations", Value: "100"},
+										{Name: "GOMAXPROCS", Value: "4"},
+										{Name: "ns/op", Value: "12.50"},
+										{Name: "B/op", Value: "0"},
+										{Name: "allocs/op", Value: "0"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := NewParser(flat.NewTestSuitesBuilder(), false)
+
+			testFile := "./../../../test/gojson/testdata/" + testCase.testFile
+
+			reader, err := os.Open(testFile)
+			if err != nil {
+				t.Fatalf("unexpected error opening file %q: %v", testFile, err)
+			}
+			testSuites, err := parser.Parse(bufio.NewScanner(reader))
+			if err != nil {
+				t.Fatalf("unexpected error parsing file: %v", err)
+			}
+
+			if !reflect.DeepEqual(testSuites, testCase.expectedSuites) {
+				t.Errorf("did not produce the correct test suites from file:\n%#v\n%#v", testCase.expectedSuites, testSuites)
+			}
+		})
+	}
+}
+
+// TestParseLongLines tests that events longer than the default limit of bufio.Scanner are parsed, and that input
+// that cannot be read is reported as an error instead of ending the input early
+func TestParseLongLines(t *testing.T) {
+	longOutput := strings.Repeat("x", 70*1024)
+	input := `{"Action":"run","Package":"package/name","Test":"TestOne"}
+{"Action":"output","Package":"package/name","Test":"TestOne","Output":"` + longOutput + `\n"}
+{"Action":"pass","Package":"package/name","Test":"TestOne","Elapsed":0.06}
+{"Action":"run","Package":"package/name","Test":"TestTwo"}
+{"Action":"pass","Package":"package/name","Test":"TestTwo","Elapsed":0.1}
+{"Action":"pass","Package":"package/name","Elapsed":0.16}
+`
+
+	testSuites, err := NewParser(flat.NewTestSuitesBuilder(), false).Parse(parser.NewScanner(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("unexpected error parsing long lines: %v", err)
+	}
+	expectedSuites := &api.TestSuites{
+		Suites: []*api.TestSuite{
+			{
+				Name:     "package/name",
+				NumTests: 2,
+				Duration: 0.16,
+				TestCases: []*api.TestCase{
+					{
+						Name:     "TestOne",
+						Duration: 0.06,
+					},
+					{
+						Name:     "TestTwo",
+						Duration: 0.1,
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(testSuites, expectedSuites) {
+		t.Errorf("did not produce the correct test suites from long lines:\n%#v\n%#v", expectedSuites, testSuites)
+	}
+
+	if _, err := NewParser(flat.NewTestSuitesBuilder(), false).Parse(bufio.NewScanner(strings.NewReader(input))); err == nil {
+		t.Errorf("expected an error for a line longer than the buffer of the scanner")
+	}
+}
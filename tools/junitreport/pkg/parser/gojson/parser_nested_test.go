@@ -0,0 +1,118 @@
+package gojson
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/nested"
+)
+
+// TestNestedParse tests that parsing the `go test -json` output in the test directory with a nested builder works as expected
+func TestNestedParse(t *testing.T) {
+	packageOne := &api.TestSuite{
+		Name:      "package/name/one",
+		NumTests:  2,
+		NumFailed: 1,
+		Duration:  0.06,
+		TestCases: []*api.TestCase{
+			{
+				Name:     "TestOne",
+				Duration: 0.05,
+				FailureOutput: &api.FailureOutput{
+					Output: "file_test.go:10: Error message from one",
+				},
+			},
+			{
+				Name:     "TestTwo",
+				Duration: 0.04,
+			},
+		},
+	}
+	packageTwo := &api.TestSuite{
+		Name:       "package/name/two",
+		NumTests:   3,
+		NumSkipped: 1,
+		Duration:   0.03,
+		TestCases: []*api.TestCase{
+			{
+				Name:     "TestSubTests",
+				Duration: 0.02,
+			},
+			{
+				Name:     "TestSubTests/pass",
+				Duration: 0.02,
+			},
+			{
+				Name: "TestSubTests/skip",
+				SkipMessage: &api.SkipMessage{
+					Message: "file_test.go:30: Skip message",
+				},
+			},
+		},
+	}
+
+	var testCases = []struct {
+		name           string
+		testFile       string
+		rootSuiteNames []string
+		expectedSuites *api.TestSuites
+	}{
+		{
+			name:     "nested",
+			testFile: "3.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:       "package",
+						NumTests:   5,
+						NumSkipped: 1,
+						NumFailed:  1,
+						Duration:   0.09,
+						Children: []*api.TestSuite{
+							{
+								Name:       "package/name",
+								NumTests:   5,
+								NumSkipped: 1,
+								NumFailed:  1,
+								Duration:   0.09,
+								Children:   []*api.TestSuite{packageOne, packageTwo},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "nested with restricted root",
+			testFile:       "3.txt",
+			rootSuiteNames: []string{"package/name/two"},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{packageTwo},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := NewParser(nested.NewTestSuitesBuilder(testCase.rootSuiteNames), false)
+
+			testFile := "./../../../test/gojson/testdata/" + testCase.testFile
+
+			reader, err := os.Open(testFile)
+			if err != nil {
+				t.Fatalf("unexpected error opening file %q: %v", testFile, err)
+			}
+			testSuites, err := parser.Parse(bufio.NewScanner(reader))
+			if err != nil {
+				t.Fatalf("unexpected error parsing file: %v", err)
+			}
+
+			if !reflect.DeepEqual(testSuites, testCase.expectedSuites) {
+				t.Errorf("did not produce the correct test suites from file:\n%s\n%s", testCase.expectedSuites, testSuites)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package gotest
+
+import (
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+const (
+	// benchmarkIterationsPropertyName is the name of the test case property holding the number of iterations
+	// a benchmark ran for. Every measurement of the benchmark is recorded in a property named after its unit.
+	benchmarkIterationsPropertyName = "iterations"
+
+	// benchmarkProcsPropertyName is the name of the test case property holding the GOMAXPROCS value a benchmark
+	// ran with
+	benchmarkProcsPropertyName = "GOMAXPROCS"
+)
+
+// NewBenchmarkTestCase returns a test case recording the result of a benchmark
+func NewBenchmarkTestCase(benchmark *Benchmark) *api.TestCase {
+	testCase := &api.TestCase{Name: benchmark.Name}
+	RecordBenchmark(testCase, benchmark)
+	return testCase
+}
+
+// RecordBenchmark records the result of a benchmark in a test case, with the number of iterations, the GOMAXPROCS
+// value and all measurements, like ns/op, B/op, allocs/op and custom metrics, as properties of the test case
+func RecordBenchmark(testCase *api.TestCase, benchmark *Benchmark) {
+	testCase.AddProperty(benchmarkIterationsPropertyName, benchmark.Iterations)
+	if len(benchmark.Procs) > 0 {
+		testCase.AddProperty(benchmarkProcsPropertyName, benchmark.Procs)
+	}
+	for _, metric := range benchmark.Metrics {
+		testCase.AddProperty(metric.Unit, metric.Value)
+	}
+}
@@ -2,6 +2,8 @@ package gotest
 
 import (
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/openshift/origin/tools/junitreport/pkg/api"
 )
@@ -18,6 +20,31 @@ func ExtractRun(line string) (string, bool) {
 	return "", false
 }
 
+// testPausePattern matches the line in verbose `go test` output that marks a test pausing to run in parallel with
+// other tests. The first submatch of this regex is the name of the test
+var testPausePattern = regexp.MustCompile(`^=== PAUSE\s+([^\s]+)$`)
+
+// ExtractPause identifies a test pausing to run in parallel with other tests.
+func ExtractPause(line string) (string, bool) {
+	if matches := testPausePattern.FindStringSubmatch(line); len(matches) > 1 && len(matches[1]) > 0 {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// testContinuePattern matches the line in verbose `go test` output that marks a paused test continuing, or the
+// output of a test continuing after that of another test in newer versions of `go test`.
+// The second submatch of this regex is the name of the test
+var testContinuePattern = regexp.MustCompile(`^=== (CONT|NAME)\s+([^\s]+)$`)
+
+// ExtractContinue identifies the continuation of a test output section.
+func ExtractContinue(line string) (string, bool) {
+	if matches := testContinuePattern.FindStringSubmatch(line); len(matches) > 2 && len(matches[2]) > 0 {
+		return matches[2], true
+	}
+	return "", false
+}
+
 // testResultPattern matches the line in verbose `go test` output that marks the result of a test.
 // The first submatch of this regex is the result of the test (PASS, FAIL, or SKIP)
 // The second submatch of this regex is the name of the test
@@ -88,7 +115,8 @@ func ExtractDuration(line string) (string, bool) {
 }
 
 const (
-	coveragePropertyName string = "coverage.statements.pct"
+	// CoveragePropertyName is the name of the test suite property holding the statement coverage of a package
+	CoveragePropertyName string = "coverage.statements.pct"
 )
 
 // ExtractProperties extracts any metadata properties of the test suite from a test output line
@@ -97,14 +125,109 @@ func ExtractProperties(line string) (map[string]string, bool) {
 	// be present on their own line or in the package result line
 	if matches := coverageOutputPattern.FindStringSubmatch(line); len(matches) > 1 && len(matches[1]) > 0 {
 		return map[string]string{
-			coveragePropertyName: matches[1],
+			CoveragePropertyName: matches[1],
 		}, true
 	}
 
 	if resultMatches := packageResultPattern.FindStringSubmatch(line); len(resultMatches) > 6 && len(resultMatches[6]) > 0 {
 		return map[string]string{
-			coveragePropertyName: resultMatches[6],
+			CoveragePropertyName: resultMatches[6],
 		}, true
 	}
 	return map[string]string{}, false
 }
+
+// buildOutputHeaderPattern matches the line in `go build` output that precedes the compiler or vet output for a
+// package, optionally followed by the test binary being built, e.g. `# package/name [package/name.test]`.
+// The first submatch of this regex is the name of the package
+var buildOutputHeaderPattern = regexp.MustCompile(`^# (\S+)( \[\S+\])?$`)
+
+// ExtractBuildOutputHeader extracts the name of the package whose build output follows a build output header line
+func ExtractBuildOutputHeader(line string) (string, bool) {
+	if matches := buildOutputHeaderPattern.FindStringSubmatch(line); len(matches) > 1 && len(matches[1]) > 0 {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// packageFailurePattern matches the `go test` output for a package that could not be tested.
+// The first submatch of this regex is the name of the package
+// The second submatch of this regex is the reason the package could not be tested (build failed or setup failed)
+var packageFailurePattern = regexp.MustCompile(`^FAIL\s+(\S+)\s+\[(build failed|setup failed)\]$`)
+
+// ExtractPackageFailure extracts the name of the package and the reason it could not be tested from a package
+// failure line
+func ExtractPackageFailure(line string) (name string, reason string, ok bool) {
+	if matches := packageFailurePattern.FindStringSubmatch(line); len(matches) > 2 && len(matches[1]) > 0 {
+		return matches[1], matches[2], true
+	}
+	return "", "", false
+}
+
+// packageWithoutTestsPattern matches the `go test` output for a package that concluded without running tests
+var packageWithoutTestsPattern = regexp.MustCompile(`^(ok|\?)\s+\S+\s+.*\[no test(s to run| files)\]$`)
+
+// MarksPackageWithoutTests determines if the line marks the end of a package that did not run any tests
+func MarksPackageWithoutTests(line string) bool {
+	return packageWithoutTestsPattern.MatchString(line)
+}
+
+// panicPattern matches the first line of the output of a panic. The first submatch of this regex is the panic message
+var panicPattern = regexp.MustCompile(`^panic: (.*)$`)
+
+// ExtractPanic extracts the message of a panic from the first line of its output
+func ExtractPanic(line string) (string, bool) {
+	if matches := panicPattern.FindStringSubmatch(line); len(matches) > 1 {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// benchmarkResultPattern matches the `go test -bench` output for the result of a benchmark.
+// The first submatch of this regex is the name of the benchmark
+// The third submatch of this regex is the GOMAXPROCS value appended to the name, if any
+// The fourth submatch of this regex is the number of iterations the benchmark ran for
+// The fifth submatch of this regex holds the measurements of the benchmark, like `1234 ns/op	56 B/op`
+var benchmarkResultPattern = regexp.MustCompile(`^(Benchmark\S*?)(-(\d+))?\s+(\d+)\s+(\S+\s+\S+(\s+\S+\s+\S+)*)\s*$`)
+
+// Benchmark holds the result of a benchmark
+type Benchmark struct {
+	// Name is the name of the benchmark as reported, including the GOMAXPROCS suffix if any
+	Name string
+	// Procs is the value of GOMAXPROCS the benchmark ran with, if it was reported
+	Procs string
+	// Iterations is the number of iterations the benchmark ran for
+	Iterations string
+	// Metrics are the measurements of the benchmark
+	Metrics []BenchmarkMetric
+}
+
+// BenchmarkMetric is a measurement reported for a benchmark, like `1234 ns/op`, `56 B/op` or a custom metric
+type BenchmarkMetric struct {
+	// Value is the measured value
+	Value string
+	// Unit is the unit of the measurement, which identifies the metric
+	Unit string
+}
+
+// ExtractBenchmark extracts the result of a benchmark from a benchmark result line
+func ExtractBenchmark(line string) (*Benchmark, bool) {
+	matches := benchmarkResultPattern.FindStringSubmatch(line)
+	if len(matches) < 6 || len(matches[1]) == 0 {
+		return nil, false
+	}
+
+	benchmark := &Benchmark{
+		Name:       matches[1] + matches[2],
+		Procs:      matches[3],
+		Iterations: matches[4],
+	}
+	fields := strings.Fields(matches[5])
+	for i := 0; i+1 < len(fields); i += 2 {
+		if _, err := strconv.ParseFloat(fields[i], 64); err != nil {
+			return nil, false
+		}
+		benchmark.Metrics = append(benchmark.Metrics, BenchmarkMetric{Value: fields[i], Unit: fields[i+1]})
+	}
+	return benchmark, true
+}
@@ -65,6 +65,48 @@ func TestExtractRunName(t *testing.T) {
 	}
 }
 
+func TestExtractPauseAndContinue(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		testLine      string
+		expectedPause string
+		expectedCont  string
+	}{
+		{
+			name:          "pause",
+			testLine:      "=== PAUSE TestName",
+			expectedPause: "TestName",
+		},
+		{
+			name:         "continue",
+			testLine:     "=== CONT  TestName/subtest",
+			expectedCont: "TestName/subtest",
+		},
+		{
+			name:         "name",
+			testLine:     "=== NAME  TestName",
+			expectedCont: "TestName",
+		},
+		{
+			name:     "run",
+			testLine: "=== RUN   TestName",
+		},
+		{
+			name:     "failed print",
+			testLine: "some other text=== CONT  TestName",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if actual, _ := ExtractPause(testCase.testLine); actual != testCase.expectedPause {
+			t.Errorf("%s: did not correctly extract paused test from line %q: expected %q, got %q", testCase.name, testCase.testLine, testCase.expectedPause, actual)
+		}
+		if actual, _ := ExtractContinue(testCase.testLine); actual != testCase.expectedCont {
+			t.Errorf("%s: did not correctly extract continued test from line %q: expected %q, got %q", testCase.name, testCase.testLine, testCase.expectedCont, actual)
+		}
+	}
+}
+
 func TestExtractResult(t *testing.T) {
 	var testCases = []struct {
 		name           string
@@ -216,17 +258,17 @@ func TestSuiteProperties(t *testing.T) {
 		{
 			name:               "basic",
 			testLine:           `coverage: 10.0% of statements`,
-			expectedProperties: map[string]string{coveragePropertyName: "10.0"},
+			expectedProperties: map[string]string{CoveragePropertyName: "10.0"},
 		},
 		{
 			name: "with package declaration",
 			testLine: `ok  	package/name 0.400s  coverage: 10.0% of statements`,
-			expectedProperties: map[string]string{coveragePropertyName: "10.0"},
+			expectedProperties: map[string]string{CoveragePropertyName: "10.0"},
 		},
 		{
 			name:               "failed print",
 			testLine:           `some other textcoverage: 10.0% of statements`,
-			expectedProperties: map[string]string{coveragePropertyName: "10.0"},
+			expectedProperties: map[string]string{CoveragePropertyName: "10.0"},
 		},
 	}
 
@@ -240,3 +282,145 @@ func TestSuiteProperties(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractPackageFailure(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		testLine       string
+		expectedName   string
+		expectedReason string
+		fail           bool
+	}{
+		{
+			name:           "build failed",
+			testLine:       "FAIL	package/name [build failed]",
+			expectedName:   "package/name",
+			expectedReason: "build failed",
+		},
+		{
+			name:           "setup failed",
+			testLine:       "FAIL	package/name [setup failed]",
+			expectedName:   "package/name",
+			expectedReason: "setup failed",
+		},
+		{
+			name:     "package result",
+			testLine: "FAIL	package/name	0.160s",
+			fail:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		name, reason, contained := ExtractPackageFailure(testCase.testLine)
+		if contained != !testCase.fail {
+			t.Errorf("%s: failed to extract package failure from line %q", testCase.name, testCase.testLine)
+		}
+		if testCase.fail {
+			continue
+		}
+		if testCase.expectedName != name || testCase.expectedReason != reason {
+			t.Errorf("%s: did not correctly extract package failure from line %q: expected %q and %q, got %q and %q", testCase.name, testCase.testLine, testCase.expectedName, testCase.expectedReason, name, reason)
+		}
+	}
+}
+
+func TestExtractBuildOutputHeader(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		testLine     string
+		expectedName string
+		fail         bool
+	}{
+		{
+			name:         "package",
+			testLine:     "# package/name",
+			expectedName: "package/name",
+		},
+		{
+			name:         "test binary",
+			testLine:     "# package/name [package/name.test]",
+			expectedName: "package/name",
+		},
+		{
+			name:     "comment",
+			testLine: "# this is not a package",
+			fail:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		name, contained := ExtractBuildOutputHeader(testCase.testLine)
+		if contained != !testCase.fail {
+			t.Errorf("%s: failed to extract package name from line %q", testCase.name, testCase.testLine)
+		}
+		if testCase.fail {
+			continue
+		}
+		if testCase.expectedName != name {
+			t.Errorf("%s: did not correctly extract package name from line %q: expected %q, got %q", testCase.name, testCase.testLine, testCase.expectedName, name)
+		}
+	}
+}
+
+func TestExtractBenchmark(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		testLine          string
+		expectedBenchmark *Benchmark
+	}{
+		{
+			name:     "basic",
+			testLine: "BenchmarkX-8   	    1000	      1234 ns/op",
+			expectedBenchmark: &Benchmark{
+				Name:       "BenchmarkX-8",
+				Procs:      "8",
+				Iterations: "1000",
+				Metrics:    []BenchmarkMetric{{Value: "1234", Unit: "ns/op"}},
+			},
+		},
+		{
+			name:     "memory and custom metrics",
+			testLine: "BenchmarkX/size-10   	    1000	      1234 ns/op	  12.50 MB/s	      56 B/op	       2 allocs/op	 3.000 custom/op",
+			expectedBenchmark: &Benchmark{
+				Name:       "BenchmarkX/size-10",
+				Procs:      "10",
+				Iterations: "1000",
+				Metrics: []BenchmarkMetric{
+					{Value: "1234", Unit: "ns/op"},
+					{Value: "12.50", Unit: "MB/s"},
+					{Value: "56", Unit: "B/op"},
+					{Value: "2", Unit: "allocs/op"},
+					{Value: "3.000", Unit: "custom/op"},
+				},
+			},
+		},
+		{
+			name:     "sub-benchmark with numeric suffix",
+			testLine: "BenchmarkX/size-10-8   	    1000	      1234 ns/op",
+			expectedBenchmark: &Benchmark{
+				Name:       "BenchmarkX/size-10-8",
+				Procs:      "8",
+				Iterations: "1000",
+				Metrics:    []BenchmarkMetric{{Value: "1234", Unit: "ns/op"}},
+			},
+		},
+		{
+			name:     "name only",
+			testLine: "BenchmarkX",
+		},
+		{
+			name:     "output",
+			testLine: "Benchmarks 10 are the best",
+		},
+	}
+
+	for _, testCase := range testCases {
+		benchmark, contained := ExtractBenchmark(testCase.testLine)
+		if contained != (testCase.expectedBenchmark != nil) {
+			t.Errorf("%s: failed to extract benchmark from line %q", testCase.name, testCase.testLine)
+		}
+		if !reflect.DeepEqual(testCase.expectedBenchmark, benchmark) {
+			t.Errorf("%s: did not correctly extract benchmark from line %q: expected %#v, got %#v", testCase.name, testCase.testLine, testCase.expectedBenchmark, benchmark)
+		}
+	}
+}
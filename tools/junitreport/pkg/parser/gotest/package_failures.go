@@ -0,0 +1,35 @@
+package gotest
+
+import (
+	"strings"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+const (
+	// packageFailedTestName is the name of the test case recorded for a package that failed outside of any of its
+	// tests, like when the test binary panics before running tests or TestMain exits with a non-zero code
+	packageFailedTestName = "[package failed]"
+)
+
+// NewPackageFailureTestCase returns a failing test case recording a package failure that is not attributed to any
+// test, with the output of the compiler, the test binary or the panic as failure output. The reason is the reason
+// given by `go test` when the package could not be tested, like `build failed`, or empty if the package failed
+// outside of its tests.
+func NewPackageFailureTestCase(reason string, output []string) *api.TestCase {
+	testCase := &api.TestCase{Name: packageFailedTestName}
+	message := reason
+	if len(reason) > 0 {
+		testCase.Name = "[" + reason + "]"
+	} else {
+		for _, line := range output {
+			if _, ok := ExtractPanic(line); ok {
+				message = line
+				break
+			}
+		}
+	}
+
+	testCase.MarkFailed(message, strings.Join(output, "\n"))
+	return testCase
+}
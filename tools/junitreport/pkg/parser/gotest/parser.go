@@ -10,6 +10,10 @@ import (
 	"github.com/openshift/origin/tools/junitreport/pkg/parser"
 )
 
+func init() {
+	parser.Register("gotest", "the output of 'go test -v'", NewParser)
+}
+
 // NewParser returns a new parser that's capable of parsing Go unit test output
 func NewParser(builder builder.TestSuitesBuilder, stream bool) parser.TestOutputParser {
 	return &testOutputParser{
@@ -23,6 +27,12 @@ type testOutputParser struct {
 	stream  bool
 }
 
+const (
+	// unknownPackageName is the name of the test suite holding the tests of a package whose output was cut short
+	// before the package result line, which is the only line naming the package
+	unknownPackageName = "unknown"
+)
+
 const (
 	stateBegin = iota
 	stateOutput
@@ -44,34 +54,168 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 	var tests map[string]*api.TestCase
 	var output map[string][]string
 	var messages map[string][]string
+	var concluded map[string]bool
+	// attempts holds the earlier attempts of tests that were run more than once, like with `go test -count`
+	var attempts map[string][]*api.TestCase
 	var currentSuite *api.TestSuite
 	var state int
 	var count int
 	var orderedTests []string
 
+	// packageOutput holds the output of the current package that is not part of any test
+	var packageOutput []string
+	// benchmarks holds the results of the benchmarks of the current package
+	var benchmarks []*api.TestCase
+	// buildOutput holds the compiler output for packages, keyed by the package name
+	buildOutput := map[string][]string{}
+	var buildPackage string
+
+	// startTest records the start of a test. A test that is started again after it concluded is run more than once,
+	// so the attempt that concluded is set aside to be merged with the later attempts once the package completes.
+	startTest := func(name string) {
+		test, started := tests[name]
+		switch {
+		case !started:
+			orderedTests = append(orderedTests, name)
+		case concluded[name]:
+			attempts[name] = append(attempts[name], completeTest(test, output[name], messages[name], true))
+			delete(output, name)
+			delete(messages, name)
+			delete(concluded, name)
+		default:
+			// the test is still in progress, so its output continues to be gathered
+			return
+		}
+		tests[name] = &api.TestCase{
+			Name: name,
+		}
+	}
+
+	// concludeTest records the result of a test found on the current line
+	concludeTest := func(name string, result api.TestResult, duration string) error {
+		test := tests[name]
+		switch result {
+		case api.TestResultPass:
+		case api.TestResultFail:
+			test.FailureOutput = &api.FailureOutput{}
+		case api.TestResultSkip:
+			test.SkipMessage = &api.SkipMessage{}
+		}
+		if err := test.SetDuration(duration); err != nil {
+			return fmt.Errorf("unexpected duration on line %d: %s", count, duration)
+		}
+		concluded[name] = true
+		return nil
+	}
+
 	for input.Scan() {
 		line := input.Text()
 		count++
 
 		log("Line %03d: %d: %s\n", count, state, line)
 
+		// benchmarks run after all tests and are recorded whenever their results are found
+		if benchmark, ok := ExtractBenchmark(line); ok {
+			log("  found benchmark %s\n", benchmark.Name)
+			benchmarks = append(benchmarks, NewBenchmarkTestCase(benchmark))
+			continue
+		}
+
+		// the package result line is only found while gathering test output if a test never concluded, like when the
+		// test binary panics or times out, or after test results if the test binary failed without printing a final
+		// result, in which case the package is complete nonetheless
+		if _, _, _, ok := ExtractPackage(line); ok && (state == stateOutput || state == stateResults) {
+			log("  found end of suite with tests in progress\n")
+			state = stateComplete
+		}
+
 		switch state {
 
 		case stateBegin:
 			// this is the first state
+			if name, ok := ExtractBuildOutputHeader(line); ok {
+				log("  found build output for %s\n", name)
+				buildPackage = name
+				continue
+			}
+
+			// a package that could not be tested is recorded with a failing test case holding its build output
+			if name, reason, ok := ExtractPackageFailure(line); ok {
+				log("  found package failure %s %s\n", name, reason)
+				output := buildOutput[name]
+				if len(output) == 0 {
+					output = packageOutput
+				}
+				suite := &api.TestSuite{Name: name}
+				suite.AddTestCase(NewPackageFailureTestCase(reason, output))
+				suites.Suites = append(suites.Suites, suite)
+				p.builder.AddSuite(suite)
+
+				delete(buildOutput, name)
+				packageOutput = nil
+				buildPackage = ""
+				continue
+			}
+
+			// a package failing before running any tests, like when the test binary panics in an init function,
+			// is recorded with a failing test case holding the output of the test binary, and
+			// a package that only ran benchmarks is recorded with a test case for every benchmark
+			if name, duration, coverage, ok := ExtractPackage(line); ok {
+				failed := strings.HasPrefix(line, "FAIL")
+				if failed || len(benchmarks) > 0 {
+					log("  found package without tests %s\n", name)
+					suite := &api.TestSuite{Name: name}
+					if props, ok := ExtractProperties(coverage); ok {
+						for k, v := range props {
+							suite.AddProperty(k, v)
+						}
+					}
+					for _, benchmark := range benchmarks {
+						suite.AddTestCase(benchmark)
+					}
+					if failed {
+						suite.AddTestCase(NewPackageFailureTestCase("", packageOutput))
+					}
+					if err := suite.SetDuration(duration); err != nil {
+						return nil, fmt.Errorf("unexpected duration on line %d: %s", count, duration)
+					}
+					suites.Suites = append(suites.Suites, suite)
+					p.builder.AddSuite(suite)
+				}
+				packageOutput = nil
+				benchmarks = nil
+				buildPackage = ""
+				continue
+			}
+
+			if MarksPackageWithoutTests(line) {
+				packageOutput = nil
+				buildPackage = ""
+				continue
+			}
+
 			name, ok := ExtractRun(line)
 			if !ok {
-				// A test that defines a test.M handler can write output prior to test execution. We will drop this because
-				// we have no place to put it, although the first test case *could* use it in the future.
-				log("  ignored output outside of suite\n")
+				// A test that defines a test.M handler can write output prior to test execution. This output is only
+				// reported if the package fails before running any tests, as we have no other place to put it.
+				if len(buildPackage) > 0 {
+					buildOutput[buildPackage] = append(buildOutput[buildPackage], line)
+				} else {
+					packageOutput = append(packageOutput, line)
+				}
+				log("  found output outside of suite\n")
 				continue
 			}
 			log("  found run command %s\n", name)
+			packageOutput = nil
+			buildPackage = ""
 
 			currentSuite = &api.TestSuite{}
 			tests = make(map[string]*api.TestCase)
 			output = make(map[string][]string)
 			messages = make(map[string][]string)
+			concluded = make(map[string]bool)
+			attempts = make(map[string][]*api.TestCase)
 
 			orderedTests = []string{name}
 			testNameStack = []string{name}
@@ -85,31 +229,27 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 			// open a new test for gathering output
 			if name, ok := ExtractRun(line); ok {
 				log("  found run command %s\n", name)
-				test, ok := tests[name]
-				if !ok {
-					test = &api.TestCase{
-						Name: name,
-					}
-					tests[name] = test
-				}
-				orderedTests = append(orderedTests, name)
+				startTest(name)
+				testNameStack = []string{name}
+				continue
+			}
+
+			// a test that paused to run in parallel with others writes its output once it continues
+			if _, ok := ExtractPause(line); ok {
+				log("  found pause\n")
+				continue
+			}
+			if name, ok := ExtractContinue(line); ok && tests[name] != nil {
+				log("  found continue %s\n", name)
 				testNameStack = []string{name}
 				continue
 			}
 
 			// transition to result mode ONLY if it matches a result at the top level
 			if result, name, depth, duration, ok := ExtractResult(line); ok && tests[name] != nil && depth == 0 {
-				test := tests[name]
 				log("  found result %s %s %s\n", result, name, duration)
-				switch result {
-				case api.TestResultPass:
-				case api.TestResultFail:
-					test.FailureOutput = &api.FailureOutput{}
-				case api.TestResultSkip:
-					test.SkipMessage = &api.SkipMessage{}
-				}
-				if err := test.SetDuration(duration); err != nil {
-					return nil, fmt.Errorf("unexpected duration on line %d: %s", count, duration)
+				if err := concludeTest(name, result, duration); err != nil {
+					return nil, err
 				}
 				testNameStack = []string{name}
 				state = stateResults
@@ -136,14 +276,30 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 				if name, ok := ExtractRun(line); ok {
 					log("  found run %s\n", name)
 					// starting a new set of runs
-					orderedTests = append(orderedTests, name)
+					startTest(name)
+					testNameStack = []string{name}
+					state = stateOutput
+					continue
+				}
+				// tests that ran in parallel with others continue and report their results at the top level
+				if _, ok := ExtractPause(line); ok {
+					log("  found pause\n")
+					continue
+				}
+				if name, ok := ExtractContinue(line); ok && tests[name] != nil {
+					log("  found continue %s\n", name)
 					testNameStack = []string{name}
-					tests[testNameStack[0]] = &api.TestCase{
-						Name: name,
-					}
 					state = stateOutput
 					continue
 				}
+				if result, name, _, duration, ok := ExtractResult(line); ok && tests[name] != nil {
+					log("  found result %s %s %s\n", result, name, duration)
+					if err := concludeTest(name, result, duration); err != nil {
+						return nil, err
+					}
+					testNameStack = []string{name}
+					continue
+				}
 				switch {
 				case line == "PASS", line == "FAIL":
 					log("  found end of suite\n")
@@ -157,6 +313,9 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 					switch {
 					case test.FailureOutput != nil, test.SkipMessage != nil:
 						messages[name] = append(messages[name], output)
+					default:
+						// the output may not belong to the test at all, like a panic in TestMain after all tests ran
+						packageOutput = append(packageOutput, output)
 					}
 				}
 				continue
@@ -165,16 +324,8 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 			// if this is a result AND we have already declared this as a test, parse it
 			if result, name, _, duration, ok := ExtractResult(output); ok && tests[name] != nil {
 				log("  found result %s %s (%d)\n", result, name, depth)
-				test := tests[name]
-				switch result {
-				case api.TestResultPass:
-				case api.TestResultFail:
-					test.FailureOutput = &api.FailureOutput{}
-				case api.TestResultSkip:
-					test.SkipMessage = &api.SkipMessage{}
-				}
-				if err := test.SetDuration(duration); err != nil {
-					return nil, fmt.Errorf("unexpected duration on line %d: %s", count, duration)
+				if err := concludeTest(name, result, duration); err != nil {
+					return nil, err
 				}
 				switch {
 				case depth >= len(testNameStack):
@@ -215,43 +366,25 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 						currentSuite.AddProperty(k, v)
 					}
 				}
-				for _, name := range orderedTests {
-					test := tests[name]
-					messageLines := messages[name]
-					var extraOutput []string
-					for i, s := range messageLines {
-						if s == "=== OUTPUT" {
-							log("test %s has OUTPUT section, %d %d\n", name, i, len(messageLines))
-							if i < len(messageLines) {
-								log("  test %s add lines: %d\n", name, len(messageLines[i+1:]))
-								extraOutput = messageLines[i+1:]
-							}
-							messageLines = messageLines[:i]
-							break
-						}
-					}
-
-					switch {
-					case test.FailureOutput != nil:
-						test.FailureOutput.Output = strings.Join(messageLines, "\n")
-
-						lines := append(output[name], extraOutput...)
-						test.SystemOut = strings.Join(lines, "\n")
-
-					case test.SkipMessage != nil:
-						test.SkipMessage.Message = strings.Join(messageLines, "\n")
-
-					default:
-						lines := append(output[name], extraOutput...)
-						test.SystemOut = strings.Join(lines, "\n")
-					}
-
-					currentSuite.AddTestCase(test)
+				// a package that passed ran all of its tests to completion, so only the tests of a package that failed
+				// or timed out can be incomplete
+				passed := strings.HasPrefix(line, "ok")
+				completeSuite(currentSuite, orderedTests, tests, output, messages, concluded, attempts, passed)
+				for _, benchmark := range benchmarks {
+					currentSuite.AddTestCase(benchmark)
 				}
+				benchmarks = nil
+				if strings.HasPrefix(line, "FAIL") && currentSuite.NumFailed == 0 {
+					// the package failed outside of its tests, like when TestMain panics or exits with a non-zero code
+					currentSuite.AddTestCase(NewPackageFailureTestCase("", packageOutput))
+				}
+				packageOutput = nil
 				if err := currentSuite.SetDuration(duration); err != nil {
 					return nil, fmt.Errorf("unexpected duration on line %d: %s", count, duration)
 				}
 				suites.Suites = append(suites.Suites, currentSuite)
+				// the builder is notified of every completed suite so that it can write the suite as soon as possible
+				p.builder.AddSuite(currentSuite)
 
 				state = stateBegin
 				continue
@@ -262,9 +395,75 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 				for k, v := range props {
 					currentSuite.AddProperty(k, v)
 				}
+				continue
 			}
+
+			// output of the test binary after the tests finished, like `exit status 1`
+			packageOutput = append(packageOutput, line)
 		}
 	}
 
+	// if the input ends before the package result line, e.g. because the test binary was killed, the tests that
+	// were started are still reported and any test that did not conclude is considered failed
+	if state != stateBegin {
+		currentSuite.Name = unknownPackageName
+		completeSuite(currentSuite, orderedTests, tests, output, messages, concluded, attempts, false)
+		for _, benchmark := range benchmarks {
+			currentSuite.AddTestCase(benchmark)
+		}
+		suites.Suites = append(suites.Suites, currentSuite)
+		p.builder.AddSuite(currentSuite)
+	}
+
 	return suites, nil
 }
+
+// completeSuite adds the tests of a package to its suite with the output recorded for them. Tests that were run more
+// than once are recorded as a single test case holding all of their attempts. Tests that did not conclude are only
+// considered incomplete if the package did not pass.
+func completeSuite(suite *api.TestSuite, orderedTests []string, tests map[string]*api.TestCase, output, messages map[string][]string, concluded map[string]bool, attempts map[string][]*api.TestCase, passed bool) {
+	for _, name := range orderedTests {
+		test := completeTest(tests[name], output[name], messages[name], passed || concluded[name])
+		if previous, rerun := attempts[name]; rerun {
+			test = api.MergeAttempts(append(previous, test))
+		}
+		suite.AddTestCase(test)
+	}
+}
+
+// completeTest records the output of a test. A test that was started but never concluded is marked as failed with
+// the output recorded for it as failure output.
+func completeTest(test *api.TestCase, output, messageLines []string, concluded bool) *api.TestCase {
+	var extraOutput []string
+	for i, s := range messageLines {
+		if s == "=== OUTPUT" {
+			log("test %s has OUTPUT section, %d %d\n", test.Name, i, len(messageLines))
+			if i < len(messageLines) {
+				log("  test %s add lines: %d\n", test.Name, len(messageLines[i+1:]))
+				extraOutput = messageLines[i+1:]
+			}
+			messageLines = messageLines[:i]
+			break
+		}
+	}
+
+	switch {
+	case !concluded:
+		lines := append(output, extraOutput...)
+		test.MarkFailed(parser.IncompleteTestMessage, strings.Join(lines, "\n"))
+
+	case test.FailureOutput != nil:
+		test.FailureOutput.Output = strings.Join(messageLines, "\n")
+
+		lines := append(output, extraOutput...)
+		test.SystemOut = strings.Join(lines, "\n")
+
+	case test.SkipMessage != nil:
+		test.SkipMessage.Message = strings.Join(messageLines, "\n")
+
+	default:
+		lines := append(output, extraOutput...)
+		test.SystemOut = strings.Join(lines, "\n")
+	}
+	return test
+}
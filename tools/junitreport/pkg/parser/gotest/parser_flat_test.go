@@ -353,6 +353,215 @@ func TestFlatParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "timed out and truncated output",
+			testFile: "18.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  2,
+						NumFailed: 1,
+						Duration:  600.005,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestOne",
+								Duration: 0.06,
+							},
+							{
+								Name: "TestTwo",
+								FailureOutput: &api.FailureOutput{
+									Message: "test did not complete",
+									Output:  "    two_test.go:10: waiting for the server\npanic: test timed out after 10m0s\n\ngoroutine 7 [running]:\ntesting.(*M).startAlarm.func1()",
+								},
+							},
+						},
+					},
+					{
+						Name:      "unknown",
+						NumTests:  3,
+						NumFailed: 2,
+						Duration:  0.01,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestThree",
+								Duration: 0.01,
+							},
+							{
+								Name: "TestFour",
+								FailureOutput: &api.FailureOutput{
+									Message: "test did not complete",
+								},
+							},
+							{
+								Name: "TestFour/subtest",
+								FailureOutput: &api.FailureOutput{
+									Message: "test did not complete",
+									Output:  "output before the test binary was killed",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "package failures outside of tests",
+			testFile: "19.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/broken",
+						NumTests:  1,
+						NumFailed: 1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "[build failed]",
+								FailureOutput: &api.FailureOutput{
+									Message: "build failed",
+									Output:  "broken/file_test.go:10:2: undefined: foo\nbroken/file_test.go:12:5: cannot use bar (type int) as type string in argument to baz",
+								},
+							},
+						},
+					},
+					{
+						Name:      "package/setup",
+						NumTests:  1,
+						NumFailed: 1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "[setup failed]",
+								FailureOutput: &api.FailureOutput{
+									Message: "setup failed",
+									Output:  "setup/file_test.go:3:8: no required module provides package example.com/missing",
+								},
+							},
+						},
+					},
+					{
+						Name:      "package/panics",
+						NumTests:  1,
+						NumFailed: 1,
+						Duration:  0.005,
+						TestCases: []*api.TestCase{
+							{
+								Name: "[package failed]",
+								FailureOutput: &api.FailureOutput{
+									Message: "panic: cannot load configuration",
+									Output:  "panic: cannot load configuration\n\ngoroutine 1 [running]:\npackage/panics.init.0()\n\t/go/src/package/panics/init.go:10 +0x39\nexit status 2",
+								},
+							},
+						},
+					},
+					{
+						Name:      "package/exits",
+						NumTests:  2,
+						NumFailed: 1,
+						Duration:  0.07,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestOne",
+								Duration: 0.06,
+							},
+							{
+								Name: "[package failed]",
+								FailureOutput: &api.FailureOutput{
+									Output: "exit status 1",
+								},
+							},
+						},
+					},
+					{
+						Name:     "package/passes",
+						NumTests: 1,
+						Duration: 0.02,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestTwo",
+								Duration: 0.01,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "repeated runs",
+			testFile: "21.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  3,
+						NumFailed: 1,
+						NumFlaky:  1,
+						Duration:  0.13,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestStable",
+								Duration: 0.03,
+							},
+							{
+								Name:     "TestFlaky",
+								Duration: 0.07,
+								FlakyFailures: []*api.RerunFailure{
+									{
+										SystemOut: "    flaky_test.go:12: timed out waiting for the server",
+									},
+								},
+							},
+							{
+								Name:          "TestBroken",
+								Duration:      0.03,
+								FailureOutput: &api.FailureOutput{},
+								SystemOut:     "    broken_test.go:8: expected 1, got 2",
+								RerunFailures: []*api.RerunFailure{
+									{
+										SystemOut: "    broken_test.go:8: expected 1, got 3",
+									},
+									{
+										SystemOut: "    broken_test.go:8: expected 1, got 4",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "parallel tests",
+			testFile: "22.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  4,
+						NumFailed: 1,
+						Duration:  0.611,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestA",
+								Duration: 0.2,
+							},
+							{
+								Name:          "TestB",
+								Duration:      0.3,
+								FailureOutput: &api.FailureOutput{},
+								SystemOut:     "    p_test.go:16: expected 1, got 2",
+							},
+							{
+								Name: "TestSerial",
+							},
+							{
+								Name:     "TestSerial/sub",
+								Duration: 0.11,
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
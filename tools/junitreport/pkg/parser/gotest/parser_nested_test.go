@@ -514,6 +514,39 @@ func TestNestedParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "parallel tests",
+			testFile: "22.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  4,
+						NumFailed: 1,
+						Duration:  0.611,
+						TestCases: []*api.TestCase{
+							{
+								Name:     "TestA",
+								Duration: 0.2,
+							},
+							{
+								Name:          "TestB",
+								Duration:      0.3,
+								FailureOutput: &api.FailureOutput{},
+								SystemOut:     "    p_test.go:16: expected 1, got 2",
+							},
+							{
+								Name: "TestSerial",
+							},
+							{
+								Name:     "TestSerial/sub",
+								Duration: 0.11,
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -0,0 +1,129 @@
+package gotest
+
+import (
+	"strings"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+const (
+	// SubtestNameDelimiter is the delimiter between the names of parent tests and their subtests
+	SubtestNameDelimiter = "/"
+)
+
+// testNode is a test case in the hierarchy of tests and subtests of a suite
+type testNode struct {
+	testCase *api.TestCase
+
+	// children are the subtests of this test, in the order in which they were run
+	children []*testNode
+}
+
+// NestSubtests replaces the test cases of tests that have subtests with child suites in all of the given suites.
+// The child suite of a test is named after the suite and the full name of the test, and holds the subtests of the
+// test. The counts of a suite encompass those of its child suites, and the duration of a child suite is that of
+// its test. Go fails a test when any of its subtests fail, so a failing parent test is only recorded as a test case
+// in its own suite if none of its subtests failed, in order for every failure to be reported once at the level it
+// occurred.
+func NestSubtests(testSuites *api.TestSuites) {
+	for _, suite := range testSuites.Suites {
+		nestSubtests(suite)
+	}
+}
+
+// nestSubtests nests the subtests in the suite and in all of its pre-existing children
+func nestSubtests(suite *api.TestSuite) {
+	for _, child := range suite.Children {
+		// nesting may remove parent tests from the child, so the counts of the child are updated in this suite
+		numTests, numSkipped, numFailed, numErrored, numFlaky := child.NumTests, child.NumSkipped, child.NumFailed, child.NumErrored, child.NumFlaky
+		nestSubtests(child)
+		suite.NumTests = suite.NumTests - numTests + child.NumTests
+		suite.NumSkipped = suite.NumSkipped - numSkipped + child.NumSkipped
+		suite.NumFailed = suite.NumFailed - numFailed + child.NumFailed
+		suite.NumErrored = suite.NumErrored - numErrored + child.NumErrored
+		suite.NumFlaky = suite.NumFlaky - numFlaky + child.NumFlaky
+	}
+
+	roots := buildTestTree(suite.TestCases)
+
+	// the counts of the suite may include those of its children, so only those of the test cases are removed
+	duration := suite.Duration
+	for _, testCase := range suite.TestCases {
+		suite.NumTests -= 1
+		switch {
+		case testCase.SkipMessage != nil:
+			suite.NumSkipped -= 1
+		case testCase.FailureOutput != nil:
+			suite.NumFailed -= 1
+		case testCase.ErrorOutput != nil:
+			suite.NumErrored -= 1
+		}
+		if len(testCase.FlakyFailures) > 0 {
+			suite.NumFlaky -= 1
+		}
+	}
+	suite.TestCases = nil
+
+	addTestNodes(suite, suite.Name, roots)
+	suite.Duration = duration
+}
+
+// buildTestTree arranges test cases under their closest ancestor test, keeping the order in which they were run
+func buildTestTree(testCases []*api.TestCase) []*testNode {
+	nodes := map[string]*testNode{}
+	for _, testCase := range testCases {
+		nodes[testCase.Name] = &testNode{testCase: testCase}
+	}
+
+	var roots []*testNode
+	for _, testCase := range testCases {
+		node := nodes[testCase.Name]
+		if parent := findParent(testCase.Name, nodes); parent != nil {
+			parent.children = append(parent.children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// findParent returns the closest ancestor of the named test, if one was recorded
+func findParent(name string, nodes map[string]*testNode) *testNode {
+	for {
+		delimiterIndex := strings.LastIndex(name, SubtestNameDelimiter)
+		if delimiterIndex < 0 {
+			return nil
+		}
+		name = name[:delimiterIndex]
+		if parent, exists := nodes[name]; exists {
+			return parent
+		}
+	}
+}
+
+// addTestNodes adds test nodes to the suite, turning those that have subtests into child suites
+func addTestNodes(suite *api.TestSuite, suiteName string, nodes []*testNode) {
+	for _, node := range nodes {
+		if len(node.children) == 0 {
+			suite.AddTestCase(node.testCase)
+			continue
+		}
+
+		child := &api.TestSuite{
+			Name: suiteName + SubtestNameDelimiter + node.testCase.Name,
+		}
+		addTestNodes(child, suiteName, node.children)
+
+		switch {
+		case node.testCase.FailureOutput != nil && child.NumFailed == 0:
+			// the parent test failed on its own account, so its failure is not recorded elsewhere
+			child.AddTestCase(node.testCase)
+		case node.testCase.SkipMessage != nil:
+			child.AddTestCase(node.testCase)
+		}
+		child.Duration = node.testCase.Duration
+
+		// the duration of the suite is restored once all of its test nodes are added
+		suite.AddChild(child)
+	}
+}
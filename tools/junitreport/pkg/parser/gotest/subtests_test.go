@@ -0,0 +1,188 @@
+package gotest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+func TestNestSubtests(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		suites         *api.TestSuites
+		expectedSuites *api.TestSuites
+	}{
+		{
+			name: "no subtests",
+			suites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					newSuite("package/name", 0.1, newTestCase("TestOne", 0.02, ""), newTestCase("TestTwo", 0.03, "failed")),
+				},
+			},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					newSuite("package/name", 0.1, newTestCase("TestOne", 0.02, ""), newTestCase("TestTwo", 0.03, "failed")),
+				},
+			},
+		},
+		{
+			name: "failing subtest",
+			suites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					newSuite("package/name", 0.1,
+						newTestCase("TestOne", 0.05, "failed"),
+						newTestCase("TestOne/pass", 0.02, ""),
+						newTestCase("TestOne/fail", 0.03, "failed"),
+						newTestCase("TestTwo", 0.01, ""),
+					),
+				},
+			},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  3,
+						NumFailed: 1,
+						Duration:  0.1,
+						TestCases: []*api.TestCase{
+							newTestCase("TestTwo", 0.01, ""),
+						},
+						Children: []*api.TestSuite{
+							{
+								Name:      "package/name/TestOne",
+								NumTests:  2,
+								NumFailed: 1,
+								Duration:  0.05,
+								TestCases: []*api.TestCase{
+									newTestCase("TestOne/pass", 0.02, ""),
+									newTestCase("TestOne/fail", 0.03, "failed"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "parent failing on its own",
+			suites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					newSuite("package/name", 0.1,
+						newTestCase("TestOne", 0.05, "failed"),
+						newTestCase("TestOne/pass", 0.02, ""),
+					),
+				},
+			},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  2,
+						NumFailed: 1,
+						Duration:  0.1,
+						Children: []*api.TestSuite{
+							{
+								Name:      "package/name/TestOne",
+								NumTests:  2,
+								NumFailed: 1,
+								Duration:  0.05,
+								TestCases: []*api.TestCase{
+									newTestCase("TestOne/pass", 0.02, ""),
+									newTestCase("TestOne", 0.05, "failed"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "deeply nested subtests in nested suites",
+			suites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package",
+						NumTests:  4,
+						NumFailed: 3,
+						Duration:  0.1,
+						Children: []*api.TestSuite{
+							newSuite("package/name", 0.1,
+								newTestCase("TestOne", 0.05, "failed"),
+								newTestCase("TestOne/sub", 0.04, "failed"),
+								newTestCase("TestOne/sub/subsub", 0.03, "failed"),
+								newTestCase("TestOne/other", 0.01, ""),
+							),
+						},
+					},
+				},
+			},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package",
+						NumTests:  2,
+						NumFailed: 1,
+						Duration:  0.1,
+						Children: []*api.TestSuite{
+							{
+								Name:      "package/name",
+								NumTests:  2,
+								NumFailed: 1,
+								Duration:  0.1,
+								Children: []*api.TestSuite{
+									{
+										Name:      "package/name/TestOne",
+										NumTests:  2,
+										NumFailed: 1,
+										Duration:  0.05,
+										TestCases: []*api.TestCase{
+											newTestCase("TestOne/other", 0.01, ""),
+										},
+										Children: []*api.TestSuite{
+											{
+												Name:      "package/name/TestOne/sub",
+												NumTests:  1,
+												NumFailed: 1,
+												Duration:  0.04,
+												TestCases: []*api.TestCase{
+													newTestCase("TestOne/sub/subsub", 0.03, "failed"),
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			NestSubtests(testCase.suites)
+			if !reflect.DeepEqual(testCase.suites, testCase.expectedSuites) {
+				t.Errorf("did not correctly nest subtests:\n%s\n%s", testCase.expectedSuites, testCase.suites)
+			}
+		})
+	}
+}
+
+func newSuite(name string, duration float64, testCases ...*api.TestCase) *api.TestSuite {
+	suite := &api.TestSuite{Name: name}
+	for _, testCase := range testCases {
+		suite.AddTestCase(testCase)
+	}
+	suite.Duration = duration
+	return suite
+}
+
+func newTestCase(name string, duration float64, failure string) *api.TestCase {
+	testCase := &api.TestCase{Name: name, Duration: duration}
+	if len(failure) > 0 {
+		testCase.MarkFailed("", failure)
+	}
+	return testCase
+}
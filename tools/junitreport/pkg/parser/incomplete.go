@@ -0,0 +1,15 @@
+package parser
+
+const (
+	// IncompleteTestMessage is the failure message of the test cases synthesized for tests that were started
+	// but never concluded, e.g. because the test binary was killed or the input was truncated
+	IncompleteTestMessage = "test did not complete"
+
+	// IncompleteSuiteTestName is the name of the failing test case synthesized for test suites that were started
+	// but never concluded
+	IncompleteSuiteTestName = "test suite did not complete"
+
+	// IncompleteSuiteMessage is the failure message of the test case synthesized for test suites that were
+	// started but never concluded
+	IncompleteSuiteMessage = "test suite did not complete"
+)
@@ -1,7 +1,9 @@
 package oscmd
 
 import (
+	"path"
 	"regexp"
+	"strconv"
 
 	"github.com/openshift/origin/tools/junitreport/pkg/api"
 	"github.com/openshift/origin/tools/junitreport/pkg/parser/stack"
@@ -26,6 +28,11 @@ func newTestDataParser() stack.TestDataParser {
 		// in the face of broken input
 		testConclusionPattern: regexp.MustCompile(`(SUCCESS|FAILURE) after ([0-9]+\.[0-9]+s): (.+:[0-9]+: executing '.*' expecting .*?)(: (.*))?$`),
 
+		// testLocationPattern matches the location of the test in a test name, and contains the following submatches:
+		//  - 1: the file declaring the test
+		//  - 2: the line in the file declaring the test
+		testLocationPattern: regexp.MustCompile(`(\S+):([0-9]+): executing '`),
+
 		// testEndPattern matches the test end bookend
 		testEndPattern: regexp.MustCompile(`=== END TEST CASE ===`),
 	}
@@ -35,6 +42,7 @@ type testDataParser struct {
 	testStartPattern       *regexp.Regexp
 	testDeclarationPattern *regexp.Regexp
 	testConclusionPattern  *regexp.Regexp
+	testLocationPattern    *regexp.Regexp
 	testEndPattern         *regexp.Regexp
 }
 
@@ -59,6 +67,25 @@ func (p *testDataParser) ExtractName(line string) (string, bool) {
 	return "", false
 }
 
+// ExtractLocation extracts the file and line declaring the test case from test output lines, as `os::cmd` test
+// names begin with the location of the test in the test script
+func (p *testDataParser) ExtractLocation(line string) (string, int, bool) {
+	name, contained := p.ExtractName(line)
+	if !contained {
+		return "", 0, false
+	}
+
+	matches := p.testLocationPattern.FindStringSubmatch(name)
+	if len(matches) < 3 || len(matches[1]) == 0 {
+		return "", 0, false
+	}
+	lineNumber, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return path.Clean(matches[1]), lineNumber, true
+}
+
 // ExtractResult extracts the test result from a test output line
 func (p *testDataParser) ExtractResult(line string) (api.TestResult, bool) {
 	if matches := p.testConclusionPattern.FindStringSubmatch(line); len(matches) > 1 && len(matches[1]) > 0 {
@@ -73,6 +73,45 @@ func TestExtractTestName(t *testing.T) {
 	}
 }
 
+func TestExtractLocation(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		testLine     string
+		expectedFile string
+		expectedLine int
+	}{
+		{
+			name:         "test declaration",
+			testLine:     `hack/test-cmd.sh:152: executing 'openshift ex validate master-config /tmp/openshift/test-cmd//master-config-broken.yaml' expecting failure and text 'ERROR'`,
+			expectedFile: "hack/test-cmd.sh",
+			expectedLine: 152,
+		},
+		{
+			name:         "test conclusion failure",
+			testLine:     `FAILURE after 30.239s: hack/../test/cmd/builds.sh:68: executing 'oc new-build -D "FROM centos:7" -o json | python -m json.tool' expecting success: the command returned the wrong error code`,
+			expectedFile: "test/cmd/builds.sh",
+			expectedLine: 68,
+		},
+		{
+			name:         "failed print: test conclusion success",
+			testLine:     `some other textSUCCESS after 0.041s: hack/../test/cmd/basicresources.sh:21: executing 'oc create -f test/testdata/resource-builder/directory' expecting success`,
+			expectedFile: "test/cmd/basicresources.sh",
+			expectedLine: 21,
+		},
+	}
+
+	parser := newTestDataParser().(*testDataParser)
+	for _, testCase := range testCases {
+		file, line, contained := parser.ExtractLocation(testCase.testLine)
+		if !contained {
+			t.Errorf("%s: failed to extract location from line %q", testCase.name, testCase.testLine)
+		}
+		if testCase.expectedFile != file || testCase.expectedLine != line {
+			t.Errorf("%s: did not correctly extract location from line %q: expected %s:%d, got %s:%d", testCase.name, testCase.testLine, testCase.expectedFile, testCase.expectedLine, file, line)
+		}
+	}
+}
+
 func TestExtractResult(t *testing.T) {
 	var testCases = []struct {
 		name           string
@@ -6,6 +6,10 @@ import (
 	"github.com/openshift/origin/tools/junitreport/pkg/parser/stack"
 )
 
+func init() {
+	parser.Register("oscmd", "the output of 'os::cmd' functions with $JUNIT_REPORT_OUTPUT set", NewParser)
+}
+
 // NewParser returns a new parser that's capable of parsing `os::cmd` test output
 func NewParser(builder builder.TestSuitesBuilder, stream bool) parser.TestOutputParser {
 	return stack.NewParser(builder, newTestDataParser(), newTestSuiteDataParser(), stream)
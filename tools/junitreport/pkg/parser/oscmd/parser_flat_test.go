@@ -29,10 +29,14 @@ func TestFlatParse(t *testing.T) {
 						TestCases: []*api.TestCase{
 							{
 								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
 								Duration: 0.123,
 							},
 							{
 								Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     24,
 								Duration: 11.123,
 							},
 						},
@@ -53,6 +57,8 @@ func TestFlatParse(t *testing.T) {
 						TestCases: []*api.TestCase{
 							{
 								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
 								Duration: 0.123,
 								FailureOutput: &api.FailureOutput{
 									Output: `=== BEGIN TEST CASE ===
@@ -66,6 +72,8 @@ There was no error output from the command.
 							},
 							{
 								Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     24,
 								Duration: 11.123,
 							},
 						},
@@ -85,10 +93,14 @@ There was no error output from the command.
 						TestCases: []*api.TestCase{
 							{
 								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
 								Duration: 0.123,
 							},
 							{
 								Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     24,
 								Duration: 11.123,
 							},
 						},
@@ -101,6 +113,8 @@ There was no error output from the command.
 						TestCases: []*api.TestCase{
 							{
 								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
 								Duration: 0.123,
 								FailureOutput: &api.FailureOutput{
 									Output: `=== BEGIN TEST CASE ===
@@ -114,6 +128,8 @@ There was no error output from the command.
 							},
 							{
 								Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     24,
 								Duration: 11.123,
 							},
 						},
@@ -133,10 +149,14 @@ There was no error output from the command.
 						TestCases: []*api.TestCase{
 							{
 								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
 								Duration: 0.123,
 							},
 							{
 								Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     24,
 								Duration: 11.123,
 							},
 						},
@@ -149,6 +169,8 @@ There was no error output from the command.
 						TestCases: []*api.TestCase{
 							{
 								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
 								Duration: 0.123,
 								FailureOutput: &api.FailureOutput{
 									Output: `=== BEGIN TEST CASE ===
@@ -162,6 +184,8 @@ There was no error output from the command.
 							},
 							{
 								Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     24,
 								Duration: 11.123,
 							},
 						},
@@ -173,10 +197,14 @@ There was no error output from the command.
 						TestCases: []*api.TestCase{
 							{
 								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
 								Duration: 0.123,
 							},
 							{
 								Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     24,
 								Duration: 11.123,
 							},
 						},
@@ -184,6 +212,45 @@ There was no error output from the command.
 				},
 			},
 		},
+		{
+			name:     "truncated",
+			testFile: "5.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package/name",
+						NumTests:  3,
+						NumFailed: 2,
+						Duration:  0.123,
+						TestCases: []*api.TestCase{
+							{
+								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
+								Duration: 0.123,
+							},
+							{
+								Name: `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File: "package/name/file.sh",
+								Line: 24,
+								FailureOutput: &api.FailureOutput{
+									Output: `=== BEGIN TEST CASE ===
+package/name/file.sh:24: executing 'some other command' expecting success
+some output before the process was killed`,
+									Message: "test did not complete",
+								},
+							},
+							{
+								Name: "test suite did not complete",
+								FailureOutput: &api.FailureOutput{
+									Message: "test suite did not complete",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -35,10 +35,14 @@ func TestNestedParse(t *testing.T) {
 								TestCases: []*api.TestCase{
 									{
 										Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     23,
 										Duration: 0.123,
 									},
 									{
 										Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     24,
 										Duration: 11.123,
 									},
 								},
@@ -61,10 +65,14 @@ func TestNestedParse(t *testing.T) {
 						TestCases: []*api.TestCase{
 							{
 								Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     23,
 								Duration: 0.123,
 							},
 							{
 								Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+								File:     "package/name/file.sh",
+								Line:     24,
 								Duration: 11.123,
 							},
 						},
@@ -91,6 +99,8 @@ func TestNestedParse(t *testing.T) {
 								TestCases: []*api.TestCase{
 									{
 										Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     23,
 										Duration: 0.123,
 										FailureOutput: &api.FailureOutput{
 											Output: `=== BEGIN TEST CASE ===
@@ -104,6 +114,8 @@ There was no error output from the command.
 									},
 									{
 										Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     24,
 										Duration: 11.123,
 									},
 								},
@@ -131,10 +143,14 @@ There was no error output from the command.
 								TestCases: []*api.TestCase{
 									{
 										Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     23,
 										Duration: 0.123,
 									},
 									{
 										Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     24,
 										Duration: 11.123,
 									},
 								},
@@ -147,6 +163,8 @@ There was no error output from the command.
 								TestCases: []*api.TestCase{
 									{
 										Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     23,
 										Duration: 0.123,
 										FailureOutput: &api.FailureOutput{
 											Output: `=== BEGIN TEST CASE ===
@@ -160,6 +178,8 @@ There was no error output from the command.
 									},
 									{
 										Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     24,
 										Duration: 11.123,
 									},
 								},
@@ -188,10 +208,14 @@ There was no error output from the command.
 								TestCases: []*api.TestCase{
 									{
 										Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     23,
 										Duration: 0.123,
 									},
 									{
 										Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+										File:     "package/name/file.sh",
+										Line:     24,
 										Duration: 11.123,
 									},
 								},
@@ -204,6 +228,8 @@ There was no error output from the command.
 										TestCases: []*api.TestCase{
 											{
 												Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+												File:     "package/name/file.sh",
+												Line:     23,
 												Duration: 0.123,
 												FailureOutput: &api.FailureOutput{
 													Output: `=== BEGIN TEST CASE ===
@@ -217,6 +243,8 @@ There was no error output from the command.
 											},
 											{
 												Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+												File:     "package/name/file.sh",
+												Line:     24,
 												Duration: 11.123,
 											},
 										},
@@ -235,10 +263,14 @@ There was no error output from the command.
 										TestCases: []*api.TestCase{
 											{
 												Name:     `package/name/file.sh:23: executing 'some command' expecting success`,
+												File:     "package/name/file.sh",
+												Line:     23,
 												Duration: 0.123,
 											},
 											{
 												Name:     `package/name/file.sh:24: executing 'some other command' expecting success`,
+												File:     "package/name/file.sh",
+												Line:     24,
 												Duration: 11.123,
 											},
 										},
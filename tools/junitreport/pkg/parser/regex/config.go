@@ -0,0 +1,67 @@
+package regex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+// Config holds the regular expressions that describe a test output format. Patterns that extract a value from a
+// line extract their first submatch, or their whole match if they do not have any submatches.
+type Config struct {
+	// TestStart matches the line marking the beginning of a test case
+	TestStart string `json:"testStart"`
+
+	// TestName matches lines holding the name of the test case
+	TestName string `json:"testName"`
+
+	// TestResult matches lines holding the result of the test case
+	TestResult string `json:"testResult"`
+
+	// Results maps the results extracted by TestResult to `pass`, `fail` or `skip`. If no results are given,
+	// common results like `ok`, `PASS`, `FAILURE` or `skipped` are recognized regardless of their case.
+	Results map[string]api.TestResult `json:"results,omitempty"`
+
+	// TestDuration optionally matches lines holding the duration of the test case, either as a number of seconds
+	// or as a duration with a unit, like `1.5s` or `300ms`
+	TestDuration string `json:"testDuration,omitempty"`
+
+	// TestMessage optionally matches lines holding the reason why the test case failed or was skipped
+	TestMessage string `json:"testMessage,omitempty"`
+
+	// TestEnd matches the line marking the completion of a test case
+	TestEnd string `json:"testEnd"`
+
+	// SuiteStart matches the line marking the beginning of a test suite
+	SuiteStart string `json:"suiteStart"`
+
+	// SuiteName matches lines holding the name of the test suite
+	SuiteName string `json:"suiteName"`
+
+	// SuiteProperties optionally matches lines holding properties of the test suite. Every named submatch of the
+	// pattern becomes a property named after the submatch.
+	SuiteProperties string `json:"suiteProperties,omitempty"`
+
+	// SuiteEnd matches the line marking the completion of a test suite
+	SuiteEnd string `json:"suiteEnd"`
+}
+
+// LoadConfig reads the JSON configuration at the given path
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading regex parser configuration: %v", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses a JSON configuration
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing regex parser configuration: %v", err)
+	}
+	return &config, nil
+}
@@ -0,0 +1,210 @@
+package regex
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser/stack"
+)
+
+// defaultResults are the results recognized when a configuration does not list any, keyed by their lower-case form
+var defaultResults = map[string]api.TestResult{
+	"ok":        api.TestResultPass,
+	"pass":      api.TestResultPass,
+	"passed":    api.TestResultPass,
+	"success":   api.TestResultPass,
+	"succeeded": api.TestResultPass,
+	"not ok":    api.TestResultFail,
+	"fail":      api.TestResultFail,
+	"failed":    api.TestResultFail,
+	"failure":   api.TestResultFail,
+	"error":     api.TestResultFail,
+	"skip":      api.TestResultSkip,
+	"skipped":   api.TestResultSkip,
+}
+
+// newDataParsers compiles the patterns of a configuration into test and test suite data parsers
+func newDataParsers(config *Config) (stack.TestDataParser, stack.TestSuiteDataParser, error) {
+	compiler := &patternCompiler{}
+	testParser := &testDataParser{
+		testStartPattern:    compiler.compile("testStart", config.TestStart, true),
+		testNamePattern:     compiler.compile("testName", config.TestName, true),
+		testResultPattern:   compiler.compile("testResult", config.TestResult, true),
+		testDurationPattern: compiler.compile("testDuration", config.TestDuration, false),
+		testMessagePattern:  compiler.compile("testMessage", config.TestMessage, false),
+		testEndPattern:      compiler.compile("testEnd", config.TestEnd, true),
+		results:             map[string]api.TestResult{},
+	}
+	suiteParser := &testSuiteDataParser{
+		suiteStartPattern:      compiler.compile("suiteStart", config.SuiteStart, true),
+		suiteNamePattern:       compiler.compile("suiteName", config.SuiteName, true),
+		suitePropertiesPattern: compiler.compile("suiteProperties", config.SuiteProperties, false),
+		suiteEndPattern:        compiler.compile("suiteEnd", config.SuiteEnd, true),
+	}
+	if compiler.err != nil {
+		return nil, nil, compiler.err
+	}
+
+	if pattern := suiteParser.suitePropertiesPattern; pattern != nil && len(strings.Join(pattern.SubexpNames(), "")) == 0 {
+		return nil, nil, fmt.Errorf("invalid pattern for suiteProperties: expected named submatches for the properties")
+	}
+
+	if len(config.Results) == 0 {
+		testParser.results = defaultResults
+		testParser.ignoreResultCase = true
+	}
+	for value, result := range config.Results {
+		switch result {
+		case api.TestResultPass, api.TestResultFail, api.TestResultSkip:
+			testParser.results[value] = result
+		default:
+			return nil, nil, fmt.Errorf("invalid result for %q: got %s, expected one of %v", value, result, []api.TestResult{api.TestResultPass, api.TestResultFail, api.TestResultSkip})
+		}
+	}
+
+	return testParser, suiteParser, nil
+}
+
+// patternCompiler compiles patterns, recording the first error encountered
+type patternCompiler struct {
+	err error
+}
+
+// compile compiles a pattern, returning nil if an optional pattern is not given
+func (c *patternCompiler) compile(name, pattern string, required bool) *regexp.Regexp {
+	if c.err != nil {
+		return nil
+	}
+	if len(pattern) == 0 {
+		if required {
+			c.err = fmt.Errorf("missing pattern for %s", name)
+		}
+		return nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		c.err = fmt.Errorf("invalid pattern for %s: %v", name, err)
+	}
+	return compiled
+}
+
+// extract extracts the first submatch of the pattern from the line, or the whole match if the pattern does not have
+// any submatches
+func extract(pattern *regexp.Regexp, line string) (string, bool) {
+	if pattern == nil {
+		return "", false
+	}
+	matches := pattern.FindStringSubmatch(line)
+	switch {
+	case len(matches) > 1 && len(matches[1]) > 0:
+		return matches[1], true
+	case len(matches) == 1 && len(matches[0]) > 0:
+		return matches[0], true
+	}
+	return "", false
+}
+
+type testDataParser struct {
+	testStartPattern    *regexp.Regexp
+	testNamePattern     *regexp.Regexp
+	testResultPattern   *regexp.Regexp
+	testDurationPattern *regexp.Regexp
+	testMessagePattern  *regexp.Regexp
+	testEndPattern      *regexp.Regexp
+
+	// results maps extracted results to test results
+	results map[string]api.TestResult
+
+	// ignoreResultCase determines if extracted results are matched regardless of their case
+	ignoreResultCase bool
+}
+
+// MarksBeginning determines if the line marks the beginning of a test case
+func (p *testDataParser) MarksBeginning(line string) bool {
+	return p.testStartPattern.MatchString(line)
+}
+
+// ExtractName extracts the name of the test case from test output lines
+func (p *testDataParser) ExtractName(line string) (string, bool) {
+	return extract(p.testNamePattern, line)
+}
+
+// ExtractResult extracts the test result from a test output line
+func (p *testDataParser) ExtractResult(line string) (api.TestResult, bool) {
+	value, contained := extract(p.testResultPattern, line)
+	if !contained {
+		return "", false
+	}
+	if p.ignoreResultCase {
+		value = strings.ToLower(value)
+	}
+	result, recognized := p.results[value]
+	return result, recognized
+}
+
+// ExtractDuration extracts the test duration from a test output line
+func (p *testDataParser) ExtractDuration(line string) (string, bool) {
+	duration, contained := extract(p.testDurationPattern, line)
+	if !contained {
+		return "", false
+	}
+	// durations without a unit are in seconds
+	if _, err := strconv.ParseFloat(duration, 64); err == nil {
+		duration += "s"
+	}
+	return duration, true
+}
+
+// ExtractMessage extracts a message (e.g. for signalling why a failure or skip occurred) from a test output line
+func (p *testDataParser) ExtractMessage(line string) (string, bool) {
+	return extract(p.testMessagePattern, line)
+}
+
+// MarksCompletion determines if the line marks the completion of a test case
+func (p *testDataParser) MarksCompletion(line string) bool {
+	return p.testEndPattern.MatchString(line)
+}
+
+type testSuiteDataParser struct {
+	suiteStartPattern      *regexp.Regexp
+	suiteNamePattern       *regexp.Regexp
+	suitePropertiesPattern *regexp.Regexp
+	suiteEndPattern        *regexp.Regexp
+}
+
+// MarksBeginning determines if the line marks the beginning of a test suite
+func (p *testSuiteDataParser) MarksBeginning(line string) bool {
+	return p.suiteStartPattern.MatchString(line)
+}
+
+// ExtractName extracts the name of the test suite from a test output line
+func (p *testSuiteDataParser) ExtractName(line string) (string, bool) {
+	return extract(p.suiteNamePattern, line)
+}
+
+// ExtractProperties extracts any metadata properties of the test suite from a test output line
+func (p *testSuiteDataParser) ExtractProperties(line string) (map[string]string, bool) {
+	if p.suitePropertiesPattern == nil {
+		return map[string]string{}, false
+	}
+	matches := p.suitePropertiesPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return map[string]string{}, false
+	}
+
+	properties := map[string]string{}
+	for i, name := range p.suitePropertiesPattern.SubexpNames() {
+		if len(name) > 0 && len(matches[i]) > 0 {
+			properties[name] = matches[i]
+		}
+	}
+	return properties, len(properties) > 0
+}
+
+// MarksCompletion determines if the line marks the completion of a test suite
+func (p *testSuiteDataParser) MarksCompletion(line string) bool {
+	return p.suiteEndPattern.MatchString(line)
+}
@@ -0,0 +1,34 @@
+package regex
+
+import (
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser/stack"
+)
+
+func init() {
+	parser.RegisterConfigurable("regex", "test output with markers matched by the regular expressions in a JSON configuration (--config)", func(path string) (parser.Factory, error) {
+		config, err := LoadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		testParser, suiteParser, err := newDataParsers(config)
+		if err != nil {
+			return nil, err
+		}
+		return func(builder builder.TestSuitesBuilder, stream bool) parser.TestOutputParser {
+			return stack.NewParser(builder, testParser, suiteParser, stream)
+		}, nil
+	})
+}
+
+// NewParser returns a new parser that's capable of parsing test output with the format described by the
+// configuration. Like `os::cmd` output, the output is expected to bound every test case and test suite with lines
+// marking their beginning and completion, and all other lines in a test case are considered its output.
+func NewParser(builder builder.TestSuitesBuilder, config *Config, stream bool) (parser.TestOutputParser, error) {
+	testParser, suiteParser, err := newDataParsers(config)
+	if err != nil {
+		return nil, err
+	}
+	return stack.NewParser(builder, testParser, suiteParser, stream), nil
+}
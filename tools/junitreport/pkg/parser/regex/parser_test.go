@@ -0,0 +1,200 @@
+package regex
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/flat"
+)
+
+const harnessConfig = `{
+	"testStart": "^>>> test",
+	"testName": "^>>> test (.+)$",
+	"testResult": "^<<< (PASSED|FAILED|SKIPPED)",
+	"testDuration": "in ([0-9.]+) seconds",
+	"testMessage": "^<<< [A-Z]+ in [0-9.]+ seconds: (.+)$",
+	"testEnd": "^<<<",
+	"suiteStart": "^### begin",
+	"suiteName": "^### begin (.+)$",
+	"suiteProperties": "^### (?P<host>[a-z]+) on (?P<platform>[a-z]+)$",
+	"suiteEnd": "^### end"
+}`
+
+const harnessOutput = `### begin harness/suite
+### builder on linux
+>>> test first
+some output
+<<< PASSED in 0.5 seconds
+>>> test second
+some other output
+<<< FAILED in 1.25 seconds: the command returned the wrong error code
+>>> test third
+<<< SKIPPED in 0 seconds: not today
+### end`
+
+func TestParse(t *testing.T) {
+	config, err := ParseConfig([]byte(harnessConfig))
+	if err != nil {
+		t.Fatalf("unexpected error parsing configuration: %v", err)
+	}
+	parser, err := NewParser(flat.NewTestSuitesBuilder(), config, false)
+	if err != nil {
+		t.Fatalf("unexpected error creating parser: %v", err)
+	}
+
+	testSuites, err := parser.Parse(bufio.NewScanner(strings.NewReader(harnessOutput)))
+	if err != nil {
+		t.Fatalf("unexpected error parsing: %v", err)
+	}
+
+	expectedSuites := &api.TestSuites{
+		Suites: []*api.TestSuite{
+			{
+				Name:       "harness/suite",
+				NumTests:   3,
+				NumSkipped: 1,
+				NumFailed:  1,
+				Duration:   1.75,
+				Properties: []*api.TestSuiteProperty{
+					{Name: "host", Value: "builder"},
+					{Name: "platform", Value: "linux"},
+				},
+				TestCases: []*api.TestCase{
+					{
+						Name:     "first",
+						Duration: 0.5,
+					},
+					{
+						Name:     "second",
+						Duration: 1.25,
+						FailureOutput: &api.FailureOutput{
+							Message: "the command returned the wrong error code",
+							Output:  ">>> test second\nsome other output\n<<< FAILED in 1.25 seconds: the command returned the wrong error code",
+						},
+					},
+					{
+						Name: "third",
+						SkipMessage: &api.SkipMessage{
+							Message: "not today",
+						},
+					},
+				},
+			},
+		},
+	}
+	// properties are extracted from a map, so their order is not deterministic
+	if properties := testSuites.Suites[0].Properties; len(properties) == 2 && properties[0].Name == "platform" {
+		properties[0], properties[1] = properties[1], properties[0]
+	}
+	if !reflect.DeepEqual(testSuites, expectedSuites) {
+		t.Errorf("did not produce the correct test suites from file:\n%s", diff.ObjectReflectDiff(expectedSuites, testSuites))
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junitreport-regex")
+	if err != nil {
+		t.Fatalf("unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{
+	"testStart": "^=== RUN",
+	"testName": "^=== RUN (.+)$",
+	"testResult": "^--- (\\w+)",
+	"results": {
+		"GOOD": "pass",
+		"BAD": "fail"
+	},
+	"testEnd": "^---",
+	"suiteStart": "^BEGIN",
+	"suiteName": "^BEGIN (.+)$",
+	"suiteEnd": "^END"
+}`), 0644); err != nil {
+		t.Fatalf("unexpected error writing configuration: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading configuration: %v", err)
+	}
+	expected := &Config{
+		TestStart:  "^=== RUN",
+		TestName:   "^=== RUN (.+)$",
+		TestResult: `^--- (\w+)`,
+		Results:    map[string]api.TestResult{"GOOD": api.TestResultPass, "BAD": api.TestResultFail},
+		TestEnd:    "^---",
+		SuiteStart: "^BEGIN",
+		SuiteName:  "^BEGIN (.+)$",
+		SuiteEnd:   "^END",
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("did not load the correct configuration:\n%s", diff.ObjectReflectDiff(expected, config))
+	}
+}
+
+func TestNewDataParsersValidatesConfig(t *testing.T) {
+	valid := Config{
+		TestStart:  "a",
+		TestName:   "b",
+		TestResult: "c",
+		TestEnd:    "d",
+		SuiteStart: "e",
+		SuiteName:  "f",
+		SuiteEnd:   "g",
+	}
+
+	var testCases = []struct {
+		name          string
+		mutate        func(config *Config)
+		expectedError string
+	}{
+		{
+			name:   "valid",
+			mutate: func(config *Config) {},
+		},
+		{
+			name:          "missing required pattern",
+			mutate:        func(config *Config) { config.TestEnd = "" },
+			expectedError: "missing pattern for testEnd",
+		},
+		{
+			name:          "malformed pattern",
+			mutate:        func(config *Config) { config.TestName = "(" },
+			expectedError: "invalid pattern for testName: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:          "properties without names",
+			mutate:        func(config *Config) { config.SuiteProperties = "(.*)" },
+			expectedError: "invalid pattern for suiteProperties: expected named submatches for the properties",
+		},
+		{
+			name:          "unknown result",
+			mutate:        func(config *Config) { config.Results = map[string]api.TestResult{"OK": "great"} },
+			expectedError: `invalid result for "OK": got great, expected one of [pass fail skip]`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config := valid
+			testCase.mutate(&config)
+			_, _, err := newDataParsers(&config)
+			switch {
+			case err == nil && len(testCase.expectedError) > 0:
+				t.Errorf("expected error %q, got none", testCase.expectedError)
+			case err != nil && err.Error() != testCase.expectedError:
+				t.Errorf("expected error %q, got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
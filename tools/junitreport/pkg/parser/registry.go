@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+)
+
+// Factory returns a new parser that hands the test suites it parses to the builder. If stream is set, the parser
+// prints a streamed subset of its input, like package result lines, as it is read.
+type Factory func(builder builder.TestSuitesBuilder, stream bool) TestOutputParser
+
+// ConfigurableFactory returns a factory for parsers that are configured by the file at the given path
+type ConfigurableFactory func(config string) (Factory, error)
+
+// registration holds a parser factory and the description of the test output it parses. Parsers that need to be
+// configured have a configurable factory instead of a factory.
+type registration struct {
+	description string
+	factory     Factory
+	configure   ConfigurableFactory
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]registration{}
+)
+
+// Register makes a parser available under the given name. Parser packages are expected to register themselves
+// when they are initialized, so that importing a package is enough to make its parser available, like for
+// `database/sql` drivers. If Register is called twice with the same name or if the factory is nil, it panics.
+func Register(name, description string, factory Factory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if factory == nil {
+		panic("parser: Register factory is nil")
+	}
+	if _, registered := registry[name]; registered {
+		panic(fmt.Sprintf("parser: Register called twice for parser %s", name))
+	}
+	registry[name] = registration{description: description, factory: factory}
+}
+
+// RegisterConfigurable makes a parser that needs to be configured available under the given name, like Register.
+// If RegisterConfigurable is called twice with the same name or if the factory is nil, it panics.
+func RegisterConfigurable(name, description string, configure ConfigurableFactory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if configure == nil {
+		panic("parser: RegisterConfigurable factory is nil")
+	}
+	if _, registered := registry[name]; registered {
+		panic(fmt.Sprintf("parser: RegisterConfigurable called twice for parser %s", name))
+	}
+	registry[name] = registration{description: description, configure: configure}
+}
+
+// Configure returns the factory of the parser registered under the given name, configured by the file at the given
+// path. Parsers registered with Register do not take a configuration, so the path must be empty for them.
+func Configure(name, config string) (Factory, error) {
+	registryLock.RLock()
+	registered, ok := registry[name]
+	registryLock.RUnlock()
+
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("unrecognized test parser type: got %s, expected one of %v", name, Names())
+	case registered.configure != nil && len(config) == 0:
+		return nil, fmt.Errorf("test parser type %s needs to be configured", name)
+	case registered.configure != nil:
+		return registered.configure(config)
+	case len(config) > 0:
+		return nil, fmt.Errorf("test parser type %s cannot be configured", name)
+	default:
+		return registered.factory, nil
+	}
+}
+
+// Lookup returns the factory of the parser registered under the given name. Parsers that need to be configured are
+// only available through Configure.
+func Lookup(name string) (Factory, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	registered, ok := registry[name]
+	return registered.factory, ok && registered.factory != nil
+}
+
+// Describe returns the description of the test output parsed by the parser registered under the given name
+func Describe(name string) string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	return registry[name].description
+}
+
+// Names returns the sorted names of all registered parsers
+func Names() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	names := []string{}
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
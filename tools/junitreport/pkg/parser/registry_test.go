@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"bufio"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+)
+
+type fakeParser struct {
+	builder builder.TestSuitesBuilder
+}
+
+func (p *fakeParser) Parse(input *bufio.Scanner) (*api.TestSuites, error) {
+	return p.builder.Build(), nil
+}
+
+func newFakeParser(builder builder.TestSuitesBuilder, stream bool) TestOutputParser {
+	return &fakeParser{builder: builder}
+}
+
+func TestRegister(t *testing.T) {
+	Register("fake", "fake test output", newFakeParser)
+
+	if factory, registered := Lookup("fake"); !registered || factory == nil {
+		t.Errorf("expected parser to be registered")
+	}
+	if _, registered := Lookup("missing"); registered {
+		t.Errorf("expected parser not to be registered")
+	}
+	if description := Describe("fake"); description != "fake test output" {
+		t.Errorf("expected parser to be described as %q, got %q", "fake test output", description)
+	}
+	if names := Names(); !reflect.DeepEqual(names, []string{"fake"}) {
+		t.Errorf("expected parser names to be %v, got %v", []string{"fake"}, names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected registering a parser twice to panic")
+		}
+	}()
+	Register("fake", "other fake test output", newFakeParser)
+}
+
+func TestConfigure(t *testing.T) {
+	Register("unconfigured", "unconfigured test output", newFakeParser)
+	RegisterConfigurable("configured", "configured test output", func(config string) (Factory, error) {
+		if config != "config.json" {
+			t.Errorf("expected configuration %q, got %q", "config.json", config)
+		}
+		return newFakeParser, nil
+	})
+
+	if _, registered := Lookup("configured"); registered {
+		t.Errorf("expected parser that needs to be configured not to be available through lookup")
+	}
+
+	var testCases = []struct {
+		name          string
+		parserType    string
+		config        string
+		expectedError string
+	}{
+		{name: "unconfigured", parserType: "unconfigured"},
+		{name: "configured", parserType: "configured", config: "config.json"},
+		{name: "configuring unconfigured", parserType: "unconfigured", config: "config.json", expectedError: "test parser type unconfigured cannot be configured"},
+		{name: "not configuring configured", parserType: "configured", expectedError: "test parser type configured needs to be configured"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			factory, err := Configure(testCase.parserType, testCase.config)
+			switch {
+			case len(testCase.expectedError) == 0 && (err != nil || factory == nil):
+				t.Errorf("expected parser factory, got error %v", err)
+			case len(testCase.expectedError) > 0 && (err == nil || err.Error() != testCase.expectedError):
+				t.Errorf("expected error %q, got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+)
+
+// MaxLineBytes is the length of the longest line of test output that can be read. `go test -json` writes every line
+// of test output as an event, so events can be much longer than the default limit of bufio.Scanner.
+const MaxLineBytes = 64 * 1024 * 1024
+
+// NewScanner returns a scanner reading test output line by line, with room for lines up to MaxLineBytes long
+func NewScanner(input io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), MaxLineBytes)
+	return scanner
+}
@@ -23,6 +23,13 @@ type TestDataParser interface {
 	MarksCompletion(line string) bool
 }
 
+// TestLocationDataParser knows how to extract the source location of a test case from raw test data. Test data
+// parsers may optionally implement it if their output records where tests are declared.
+type TestLocationDataParser interface {
+	// ExtractLocation extracts the source file and line declaring the test case from a test output line
+	ExtractLocation(line string) (file string, lineNumber int, succeeded bool)
+}
+
 // TestSuiteDataParser knows how to take raw test suite data and extract the useful information from it
 type TestSuiteDataParser interface {
 	// MarksBeginning determines if the line marks the beginning of a test suite
@@ -46,10 +46,11 @@ type testOutputParser struct {
 func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error) {
 	inProgress := NewTestSuiteStack()
 
-	var currentTest *api.TestCase
+	currentTest := &api.TestCase{}
 	var currentResult api.TestResult
 	var currentOutput []string
 	var currentMessage string
+	var inTest bool
 
 	for input.Scan() {
 		line := input.Text()
@@ -60,12 +61,19 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 			currentResult = api.TestResultFail
 			currentOutput = []string{}
 			currentMessage = ""
+			inTest = true
 		}
 
 		if name, contained := p.testParser.ExtractName(line); contained {
 			currentTest.Name = name
 		}
 
+		if locationParser, ok := p.testParser.(TestLocationDataParser); ok {
+			if file, lineNumber, contained := locationParser.ExtractLocation(line); contained {
+				currentTest.SetLocation(file, lineNumber)
+			}
+		}
+
 		if result, contained := p.testParser.ExtractResult(line); contained {
 			currentResult = result
 		}
@@ -98,6 +106,7 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 
 			inProgress.Peek().AddTestCase(currentTest)
 			currentTest = &api.TestCase{}
+			inTest = false
 		}
 
 		if p.suiteParser.MarksBeginning(line) {
@@ -107,12 +116,12 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 			isTestOutput = false
 		}
 
-		if name, contained := p.suiteParser.ExtractName(line); contained {
+		if name, contained := p.suiteParser.ExtractName(line); contained && inProgress.Peek() != nil {
 			inProgress.Peek().Name = name
 			isTestOutput = false
 		}
 
-		if properties, contained := p.suiteParser.ExtractProperties(line); contained {
+		if properties, contained := p.suiteParser.ExtractProperties(line); contained && inProgress.Peek() != nil {
 			for propertyName := range properties {
 				inProgress.Peek().AddProperty(propertyName, properties[propertyName])
 			}
@@ -125,7 +134,9 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 			}
 
 			// if we encounter the end of a suite, we remove the suite at the head of the in progress stack
-			p.builder.AddSuite(inProgress.Pop())
+			if suite := inProgress.Pop(); suite != nil {
+				p.builder.AddSuite(suite)
+			}
 			isTestOutput = false
 		}
 
@@ -134,5 +145,20 @@ func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error)
 			currentOutput = append(currentOutput, line)
 		}
 	}
+
+	// if the input ends early, e.g. because the process producing it was killed, the test in progress and the
+	// suites that were never concluded are reported as failed
+	if inTest && inProgress.Peek() != nil {
+		currentTest.MarkFailed(parser.IncompleteTestMessage, strings.Join(currentOutput, "\n"))
+		inProgress.Peek().AddTestCase(currentTest)
+	}
+	for !inProgress.IsEmpty() {
+		suite := inProgress.Pop()
+		testCase := &api.TestCase{Name: parser.IncompleteSuiteTestName}
+		testCase.MarkFailed(parser.IncompleteSuiteMessage, "")
+		suite.AddTestCase(testCase)
+		p.builder.AddSuite(suite)
+	}
+
 	return p.builder.Build(), nil
 }
@@ -0,0 +1,168 @@
+package tap
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+// versionPattern matches the version line that begins a TAP stream
+var versionPattern = regexp.MustCompile(`^TAP version (\d+)$`)
+
+// ExtractVersion extracts the TAP version from a version line
+func ExtractVersion(line string) (string, bool) {
+	if matches := versionPattern.FindStringSubmatch(line); len(matches) > 1 && len(matches[1]) > 0 {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// planPattern matches the plan line of a TAP stream and has the following submatches:
+//   - 1: the number of tests planned
+//   - 3: the reason given for skipping the stream, if any
+var planPattern = regexp.MustCompile(`^1\.\.(\d+)\s*(#\s*(.*))?$`)
+
+// ExtractPlan extracts the number of planned tests from a plan line
+func ExtractPlan(line string) (count int, ok bool) {
+	matches := planPattern.FindStringSubmatch(line)
+	if len(matches) < 2 || len(matches[1]) == 0 {
+		return 0, false
+	}
+	count, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, false
+	}
+	return count, true
+}
+
+// testPointPattern matches a test point line and has the following submatches:
+//   - 1: the test status, `ok` or `not ok`
+//   - 2: the test number, if any
+//   - 3: the test description, if any
+//   - 5: the directive, if any
+var testPointPattern = regexp.MustCompile(`^(ok|not ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(#\s*(.*))?$`)
+
+// directivePattern matches the SKIP and TODO directives of a test point and has the following submatches:
+//   - 1: the directive
+//   - 2: the reason given for the directive
+var directivePattern = regexp.MustCompile(`(?i)^(skip|todo)\S*\s*(.*)$`)
+
+// timePattern matches the non-standard time directive emitted by node-tap, e.g. `# time=12.3ms`
+var timePattern = regexp.MustCompile(`time=(\d+(\.\d+)?m?s)`)
+
+// TestPoint holds the information contained in a TAP test point line
+type TestPoint struct {
+	// Number is the test number, or zero if the test point does not have one
+	Number int
+	// Description is the description of the test, if any
+	Description string
+	// Result is the result of the test with directives taken into account
+	Result api.TestResult
+	// Message is the reason given by a SKIP or TODO directive
+	Message string
+	// Duration is the duration recorded with a time directive, if any
+	Duration string
+}
+
+// ExtractTestPoint extracts the information held in a test point line. Failing tests with a TODO directive are
+// not failures according to the TAP specification, so they are considered skipped.
+func ExtractTestPoint(line string) (*TestPoint, bool) {
+	matches := testPointPattern.FindStringSubmatch(line)
+	if len(matches) < 2 || len(matches[1]) == 0 {
+		return nil, false
+	}
+
+	point := &TestPoint{
+		Description: matches[3],
+		Result:      api.TestResultPass,
+	}
+	if len(matches[2]) > 0 {
+		number, err := strconv.Atoi(matches[2])
+		if err != nil {
+			return nil, false
+		}
+		point.Number = number
+	}
+	if matches[1] == "not ok" {
+		point.Result = api.TestResultFail
+	}
+
+	directive := matches[5]
+	if directiveMatches := directivePattern.FindStringSubmatch(directive); len(directiveMatches) > 1 {
+		switch strings.ToLower(directiveMatches[1]) {
+		case "skip":
+			point.Result = api.TestResultSkip
+			point.Message = directiveMatches[2]
+		case "todo":
+			if point.Result == api.TestResultFail {
+				point.Result = api.TestResultSkip
+				point.Message = strings.TrimSpace("TODO " + directiveMatches[2])
+			}
+		}
+	}
+	if timeMatches := timePattern.FindStringSubmatch(directive); len(timeMatches) > 1 {
+		point.Duration = timeMatches[1]
+	}
+
+	return point, true
+}
+
+// subtestPattern matches the comment that introduces a subtest. The first submatch is the name of the subtest
+var subtestPattern = regexp.MustCompile(`^# Subtest(?::\s*(.*))?$`)
+
+// ExtractSubtest extracts the name of a subtest from the comment introducing it
+func ExtractSubtest(line string) (string, bool) {
+	if matches := subtestPattern.FindStringSubmatch(line); len(matches) > 1 {
+		return strings.TrimSpace(matches[1]), true
+	}
+	return "", false
+}
+
+// bailOutPattern matches the line emitted when a TAP producer aborts. The first submatch is the reason
+var bailOutPattern = regexp.MustCompile(`^Bail out!\s*(.*)$`)
+
+// ExtractBailOut extracts the reason for aborting the test run from a bail out line
+func ExtractBailOut(line string) (string, bool) {
+	if matches := bailOutPattern.FindStringSubmatch(line); len(matches) > 1 {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// MarksYAMLBeginning determines if the line starts a YAML diagnostic block
+func MarksYAMLBeginning(line string) bool {
+	return strings.TrimSpace(line) == "---"
+}
+
+// MarksYAMLCompletion determines if the line ends a YAML diagnostic block
+func MarksYAMLCompletion(line string) bool {
+	return strings.TrimSpace(line) == "..."
+}
+
+// yamlScalarPattern matches a top-level scalar mapping in a YAML diagnostic block. The first submatch is the key
+// and the second submatch is the value
+var yamlScalarPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*):\s*(.*)$`)
+
+// ExtractYAMLScalar extracts the value of a top-level scalar key from the lines of a YAML diagnostic block.
+// Only the simple quoted and unquoted single-line scalars that TAP producers emit are supported.
+func ExtractYAMLScalar(lines []string, key string) (string, bool) {
+	for _, line := range lines {
+		matches := yamlScalarPattern.FindStringSubmatch(line)
+		if len(matches) < 3 || matches[1] != key {
+			continue
+		}
+		value := strings.TrimSpace(matches[2])
+		switch {
+		case len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`):
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+		case len(value) >= 2 && strings.HasPrefix(value, `'`) && strings.HasSuffix(value, `'`):
+			value = strings.Replace(value[1:len(value)-1], `''`, `'`, -1)
+		}
+		return value, len(value) > 0
+	}
+	return "", false
+}
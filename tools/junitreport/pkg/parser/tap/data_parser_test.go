@@ -0,0 +1,234 @@
+package tap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+)
+
+func TestExtractPlan(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		testLine      string
+		expectedCount int
+		expectedOk    bool
+	}{
+		{
+			name:          "basic",
+			testLine:      "1..4",
+			expectedCount: 4,
+			expectedOk:    true,
+		},
+		{
+			name:          "skipped",
+			testLine:      "1..0 # SKIP no database",
+			expectedCount: 0,
+			expectedOk:    true,
+		},
+		{
+			name:     "not a plan",
+			testLine: "ok 1..4",
+		},
+	}
+
+	for _, testCase := range testCases {
+		count, ok := ExtractPlan(testCase.testLine)
+		if ok != testCase.expectedOk {
+			t.Errorf("%s: did not correctly determine if line %q was a plan: expected %t, got %t", testCase.name, testCase.testLine, testCase.expectedOk, ok)
+		}
+		if count != testCase.expectedCount {
+			t.Errorf("%s: did not correctly extract plan from line %q: expected %d, got %d", testCase.name, testCase.testLine, testCase.expectedCount, count)
+		}
+	}
+}
+
+func TestExtractTestPoint(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		testLine      string
+		expectedPoint *TestPoint
+	}{
+		{
+			name:     "passing",
+			testLine: "ok 1 - Input file opened",
+			expectedPoint: &TestPoint{
+				Number:      1,
+				Description: "Input file opened",
+				Result:      api.TestResultPass,
+			},
+		},
+		{
+			name:     "failing",
+			testLine: "not ok 2 - First line of the input valid",
+			expectedPoint: &TestPoint{
+				Number:      2,
+				Description: "First line of the input valid",
+				Result:      api.TestResultFail,
+			},
+		},
+		{
+			name:     "bare",
+			testLine: "ok",
+			expectedPoint: &TestPoint{
+				Result: api.TestResultPass,
+			},
+		},
+		{
+			name:     "description without dash",
+			testLine: "ok 3 description",
+			expectedPoint: &TestPoint{
+				Number:      3,
+				Description: "description",
+				Result:      api.TestResultPass,
+			},
+		},
+		{
+			name:     "skip",
+			testLine: "ok 3 - Read the rest of the file # SKIP no file to read",
+			expectedPoint: &TestPoint{
+				Number:      3,
+				Description: "Read the rest of the file",
+				Result:      api.TestResultSkip,
+				Message:     "no file to read",
+			},
+		},
+		{
+			name:     "lowercase skipped",
+			testLine: "ok 3 # skipped",
+			expectedPoint: &TestPoint{
+				Number: 3,
+				Result: api.TestResultSkip,
+			},
+		},
+		{
+			name:     "failing todo",
+			testLine: "not ok 4 - Summarized correctly # TODO Not written yet",
+			expectedPoint: &TestPoint{
+				Number:      4,
+				Description: "Summarized correctly",
+				Result:      api.TestResultSkip,
+				Message:     "TODO Not written yet",
+			},
+		},
+		{
+			name:     "passing todo",
+			testLine: "ok 4 - Summarized correctly # TODO Not written yet",
+			expectedPoint: &TestPoint{
+				Number:      4,
+				Description: "Summarized correctly",
+				Result:      api.TestResultPass,
+			},
+		},
+		{
+			name:     "time",
+			testLine: "ok 1 - child # time=12.5ms",
+			expectedPoint: &TestPoint{
+				Number:      1,
+				Description: "child",
+				Result:      api.TestResultPass,
+				Duration:    "12.5ms",
+			},
+		},
+		{
+			name:     "not a test point",
+			testLine: "okay then",
+		},
+	}
+
+	for _, testCase := range testCases {
+		point, _ := ExtractTestPoint(testCase.testLine)
+		if !reflect.DeepEqual(point, testCase.expectedPoint) {
+			t.Errorf("%s: did not correctly extract test point from line %q: expected %#v, got %#v", testCase.name, testCase.testLine, testCase.expectedPoint, point)
+		}
+	}
+}
+
+func TestExtractSubtest(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		testLine     string
+		expectedName string
+		expectedOk   bool
+	}{
+		{
+			name:         "named",
+			testLine:     "# Subtest: foo.tap",
+			expectedName: "foo.tap",
+			expectedOk:   true,
+		},
+		{
+			name:       "unnamed",
+			testLine:   "# Subtest",
+			expectedOk: true,
+		},
+		{
+			name:     "comment",
+			testLine: "# Subtests are great",
+		},
+	}
+
+	for _, testCase := range testCases {
+		name, ok := ExtractSubtest(testCase.testLine)
+		if ok != testCase.expectedOk {
+			t.Errorf("%s: did not correctly determine if line %q introduced a subtest: expected %t, got %t", testCase.name, testCase.testLine, testCase.expectedOk, ok)
+		}
+		if name != testCase.expectedName {
+			t.Errorf("%s: did not correctly extract subtest name from line %q: expected %q, got %q", testCase.name, testCase.testLine, testCase.expectedName, name)
+		}
+	}
+}
+
+func TestExtractYAMLScalar(t *testing.T) {
+	lines := []string{
+		"message: 'it''s broken'",
+		`quoted: "expected\ttrue"`,
+		"duration_ms: 3.2",
+		"data:",
+		"  message: nested",
+	}
+
+	var testCases = []struct {
+		name          string
+		key           string
+		expectedValue string
+		expectedOk    bool
+	}{
+		{
+			name:          "single quoted",
+			key:           "message",
+			expectedValue: "it's broken",
+			expectedOk:    true,
+		},
+		{
+			name:          "double quoted",
+			key:           "quoted",
+			expectedValue: "expected\ttrue",
+			expectedOk:    true,
+		},
+		{
+			name:          "unquoted",
+			key:           "duration_ms",
+			expectedValue: "3.2",
+			expectedOk:    true,
+		},
+		{
+			name: "mapping",
+			key:  "data",
+		},
+		{
+			name: "missing",
+			key:  "severity",
+		},
+	}
+
+	for _, testCase := range testCases {
+		value, ok := ExtractYAMLScalar(lines, testCase.key)
+		if ok != testCase.expectedOk {
+			t.Errorf("%s: did not correctly determine if key %q was present: expected %t, got %t", testCase.name, testCase.key, testCase.expectedOk, ok)
+		}
+		if value != testCase.expectedValue {
+			t.Errorf("%s: did not correctly extract key %q: expected %q, got %q", testCase.name, testCase.key, testCase.expectedValue, value)
+		}
+	}
+}
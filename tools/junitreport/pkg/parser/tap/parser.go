@@ -0,0 +1,375 @@
+package tap
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/nested"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser"
+)
+
+func init() {
+	parser.Register("tap", "TAP (Test Anything Protocol) streams, e.g. from 'prove --verbose'", NewParser)
+}
+
+// NewParser returns a new parser that's capable of parsing TAP (Test Anything Protocol) output
+func NewParser(builder builder.TestSuitesBuilder, stream bool) parser.TestOutputParser {
+	return &testOutputParser{
+		builder: builder,
+		stream:  stream,
+	}
+}
+
+const (
+	// defaultSuiteName is the name of the test suite holding the tests of a TAP stream, as TAP does not name streams
+	defaultSuiteName = "tap"
+
+	// planTestName is the name of the failing test case recorded when a stream does not run the planned tests
+	planTestName = "plan"
+
+	// bailOutTestName is the name of the failing test case recorded when a TAP producer aborts
+	bailOutTestName = "Bail out!"
+
+	// indentation is the indentation of subtests relative to their parent
+	indentation = 4
+)
+
+// testOutputParser parses TAP streams. Every TAP stream, and every subtest within it, becomes a test suite,
+// and every test point becomes a test case. Subtest suites are named with the name of their parent and the
+// subtest separated by `/`, so nested test suite builders reproduce the subtest hierarchy.
+type testOutputParser struct {
+	builder builder.TestSuitesBuilder
+	stream  bool
+
+	// frames holds the stream and the subtests that are currently being parsed, innermost last
+	frames []*frame
+
+	// streams is the number of TAP streams that have been started
+	streams int
+}
+
+// frame holds the state of a TAP stream or subtest that is being parsed
+type frame struct {
+	// suite is the suite holding the test points of the frame. Until the frame is closed, the name of the
+	// suite is relative to the parent frame
+	suite *api.TestSuite
+
+	// indent is the indentation of the lines belonging to the frame
+	indent int
+
+	// plan is the number of planned tests, or -1 if no plan has been seen
+	plan int
+
+	// count is the number of test points that have been seen
+	count int
+
+	// current is the last test point, which may still be followed by a YAML diagnostic block
+	current *testPoint
+
+	// inYAML determines if the lines being read are part of a YAML diagnostic block
+	inYAML bool
+
+	// output holds the lines seen since the last test point, which are considered output of the next one
+	output []string
+
+	// subtestName is the name announced for the next subtest, if any
+	subtestName string
+
+	// closed holds the suites of finished subtests, named relative to this frame
+	closed []*api.TestSuite
+}
+
+// testPoint holds a test case that has been read but not yet added to its suite
+type testPoint struct {
+	testCase *api.TestCase
+	result   api.TestResult
+	message  string
+	output   []string
+	yaml     []string
+
+	// concludesSubtest determines if the test point only concludes a subtest whose suite accounts for its result,
+	// in which case it is not recorded as a test case of its own
+	concludesSubtest bool
+}
+
+// Parse parses TAP output into test suites. Lines are attributed to a stream or subtest by their indentation.
+// Comments and other lines that are not part of the protocol are considered output of the test point following
+// them, and YAML diagnostic blocks are considered output of the test point preceding them.
+func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error) {
+	p.frames = nil
+
+	for input.Scan() {
+		line := input.Text()
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		if current := p.top(); current != nil && current.inYAML {
+			if MarksYAMLCompletion(trimmed) {
+				current.inYAML = false
+				p.finishTest(current)
+				continue
+			}
+			current.current.yaml = append(current.current.yaml, strings.TrimPrefix(line, strings.Repeat(" ", current.indent+2)))
+			continue
+		}
+
+		if _, ok := ExtractVersion(line); ok {
+			p.closeFrames()
+			p.openStream()
+			continue
+		}
+
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if name, ok := ExtractSubtest(trimmed); ok {
+			parent := p.top()
+			if current := p.ensureFrame(indent); parent != nil && current != parent {
+				// the comment is indented like the subtest it introduces
+				current.suite.Name = name
+			} else {
+				current.subtestName = name
+			}
+			continue
+		}
+
+		if reason, ok := ExtractBailOut(trimmed); ok {
+			current := p.ensureFrame(indent)
+			p.finishTest(current)
+			testCase := &api.TestCase{Name: bailOutTestName}
+			testCase.MarkFailed(reason, strings.Join(append(current.output, line), "\n"))
+			current.suite.AddTestCase(testCase)
+			current.output = nil
+			// plans cannot be fulfilled once the producer has aborted, and the abort is already recorded as a failure
+			for _, f := range p.frames {
+				f.plan = -1
+			}
+			p.closeFrames()
+			continue
+		}
+
+		if count, ok := ExtractPlan(trimmed); ok {
+			current, subtest := p.frameAt(indent)
+			p.finishTest(current)
+			if subtest != nil {
+				p.adoptSubtest(current, subtest)
+			}
+			current.plan = count
+			continue
+		}
+
+		if point, ok := ExtractTestPoint(trimmed); ok {
+			if err := p.addTestPoint(point, line, indent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if current := p.top(); current != nil && current.current != nil && MarksYAMLBeginning(trimmed) && indent > current.indent {
+			current.inYAML = true
+			continue
+		}
+
+		// all other lines, like comments, are output of the test point that follows them
+		current := p.top()
+		if current == nil {
+			current = p.openStream()
+		}
+		p.finishTest(current)
+		current.output = append(current.output, line)
+	}
+
+	p.closeFrames()
+	return p.builder.Build(), nil
+}
+
+// top returns the innermost frame, if any
+func (p *testOutputParser) top() *frame {
+	if len(p.frames) == 0 {
+		return nil
+	}
+	return p.frames[len(p.frames)-1]
+}
+
+// openStream starts a new TAP stream
+func (p *testOutputParser) openStream() *frame {
+	p.streams++
+	name := defaultSuiteName
+	if p.streams > 1 {
+		name = fmt.Sprintf("%s-%d", defaultSuiteName, p.streams)
+	}
+	stream := &frame{
+		suite: &api.TestSuite{Name: name},
+		plan:  -1,
+	}
+	p.frames = []*frame{stream}
+	return stream
+}
+
+// openSubtest starts a new subtest at the given indentation within the innermost frame
+func (p *testOutputParser) openSubtest(indent int) *frame {
+	parent := p.top()
+	p.finishTest(parent)
+	subtest := &frame{
+		suite:  &api.TestSuite{Name: parent.subtestName},
+		indent: indent,
+		plan:   -1,
+		output: parent.output,
+	}
+	parent.subtestName = ""
+	parent.output = nil
+	p.frames = append(p.frames, subtest)
+	return subtest
+}
+
+// ensureFrame returns the innermost frame, starting a stream if none is in progress and starting a subtest if
+// the line is indented further than the innermost frame.
+func (p *testOutputParser) ensureFrame(indent int) *frame {
+	current := p.top()
+	if current == nil {
+		current = p.openStream()
+	}
+	if indent >= current.indent+indentation {
+		current = p.openSubtest(indent)
+	}
+	return current
+}
+
+// frameAt returns the frame that a line at the given indentation belongs to, like ensureFrame, but concludes any
+// subtests that are indented further than the line. The outermost concluded subtest is returned so that the
+// caller can record it in its parent.
+func (p *testOutputParser) frameAt(indent int) (current, subtest *frame) {
+	for len(p.frames) > 1 && indent < p.top().indent {
+		if subtest != nil {
+			// a more deeply nested subtest was not concluded by a test point of its own
+			p.adoptSubtest(p.top(), subtest)
+		}
+		subtest = p.closeFrame()
+	}
+	return p.ensureFrame(indent), subtest
+}
+
+// addTestPoint records a test point. A test point that is indented less than the innermost frame concludes
+// the subtests that are indented further than it.
+func (p *testOutputParser) addTestPoint(point *TestPoint, line string, indent int) error {
+	current, subtest := p.frameAt(indent)
+	p.finishTest(current)
+	current.count++
+
+	name := point.Description
+	if len(name) == 0 && subtest != nil {
+		name = subtest.suite.Name
+	}
+	if len(name) == 0 {
+		name = fmt.Sprintf("test %d", current.count)
+	}
+
+	testCase := &api.TestCase{Name: name}
+	if len(point.Duration) > 0 {
+		if err := testCase.SetDuration(point.Duration); err != nil {
+			return fmt.Errorf("unexpected duration in test point %q: %v", line, err)
+		}
+	}
+	current.current = &testPoint{
+		testCase: testCase,
+		result:   point.Result,
+		message:  point.Message,
+		output:   current.output,
+	}
+	current.output = nil
+
+	if subtest != nil {
+		if len(subtest.suite.Name) == 0 {
+			subtest.suite.Name = name
+		}
+		p.adoptSubtest(current, subtest)
+		// the test point concluding a subtest is only recorded if the subtest does not already account for its result
+		if subtest.suite.NumTests > 0 && (point.Result != api.TestResultFail || subtest.suite.NumFailed > 0) {
+			current.current.concludesSubtest = true
+		}
+	}
+
+	if p.stream && len(p.frames) == 1 {
+		fmt.Fprintln(os.Stdout, line)
+	}
+	return nil
+}
+
+// finishTest adds the last test point of a frame to the frame's suite
+func (p *testOutputParser) finishTest(f *frame) {
+	if f == nil || f.current == nil {
+		return
+	}
+	point := f.current
+	f.current = nil
+	f.inYAML = false
+	if point.concludesSubtest {
+		return
+	}
+
+	if duration, ok := ExtractYAMLScalar(point.yaml, "duration_ms"); ok && point.testCase.Duration == 0 {
+		// a malformed duration is not worth failing the parse for, so it is ignored
+		_ = point.testCase.SetDuration(duration + "ms")
+	}
+
+	output := strings.Join(append(point.output, point.yaml...), "\n")
+	switch point.result {
+	case api.TestResultFail:
+		message, _ := ExtractYAMLScalar(point.yaml, "message")
+		point.testCase.MarkFailed(message, output)
+	case api.TestResultSkip:
+		point.testCase.MarkSkipped(point.message)
+	default:
+		point.testCase.SystemOut = output
+	}
+	f.suite.AddTestCase(point.testCase)
+}
+
+// closeFrame finishes the innermost frame, recording a failure if it did not run the planned number of tests
+func (p *testOutputParser) closeFrame() *frame {
+	f := p.top()
+	p.frames = p.frames[:len(p.frames)-1]
+	p.finishTest(f)
+
+	if f.plan >= 0 && f.plan != f.count {
+		testCase := &api.TestCase{Name: planTestName}
+		testCase.MarkFailed(fmt.Sprintf("planned %d tests but ran %d", f.plan, f.count), strings.Join(f.output, "\n"))
+		f.suite.AddTestCase(testCase)
+	}
+	return f
+}
+
+// closeFrames finishes all frames, handing the suites of the stream and its subtests to the builder
+func (p *testOutputParser) closeFrames() {
+	for len(p.frames) > 0 {
+		f := p.closeFrame()
+		if parent := p.top(); parent != nil {
+			p.adoptSubtest(parent, f)
+			continue
+		}
+
+		for _, suite := range f.closed {
+			suite.Name = f.suite.Name + nested.TestSuiteNameDelimiter + suite.Name
+			p.builder.AddSuite(suite)
+		}
+		p.builder.AddSuite(f.suite)
+	}
+}
+
+// adoptSubtest records the suites of a finished subtest in its parent frame, naming them relative to the parent.
+// Subtests that were not named by a comment or by the test point concluding them are named after their position.
+func (p *testOutputParser) adoptSubtest(parent, subtest *frame) {
+	if len(subtest.suite.Name) == 0 {
+		subtest.suite.Name = fmt.Sprintf("test %d", parent.count+1)
+	}
+	for _, suite := range subtest.closed {
+		suite.Name = subtest.suite.Name + nested.TestSuiteNameDelimiter + suite.Name
+		parent.closed = append(parent.closed, suite)
+	}
+	parent.closed = append(parent.closed, subtest.suite)
+}
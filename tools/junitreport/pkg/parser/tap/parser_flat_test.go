@@ -0,0 +1,197 @@
+package tap
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/flat"
+)
+
+// TestFlatParse tests that parsing the TAP output in the test directory with a flat builder works as expected
+func TestFlatParse(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		testFile       string
+		expectedSuites *api.TestSuites
+	}{
+		{
+			name:     "directives and diagnostics",
+			testFile: "1.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:       "tap",
+						NumTests:   4,
+						NumSkipped: 2,
+						NumFailed:  1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "Input file opened",
+							},
+							{
+								Name: "First line of the input valid",
+								FailureOutput: &api.FailureOutput{
+									Message: "First line invalid",
+									Output:  "message: 'First line invalid'\nseverity: fail\ndata:\n  got: 'Flirble'\n  expect: 'Fnible'",
+								},
+							},
+							{
+								Name: "Read the rest of the file",
+								SkipMessage: &api.SkipMessage{
+									Message: "no file to read",
+								},
+							},
+							{
+								Name: "Summarized correctly",
+								SkipMessage: &api.SkipMessage{
+									Message: "TODO Not written yet",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "subtests",
+			testFile: "2.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:     "tap/foo.tap",
+						NumTests: 2,
+						Duration: 0.012,
+						TestCases: []*api.TestCase{
+							{
+								Name: "test 1",
+							},
+							{
+								Name:     "this passed",
+								Duration: 0.012,
+							},
+						},
+					},
+					{
+						Name:     "tap/bar.tap/nested",
+						NumTests: 1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "deep",
+							},
+						},
+					},
+					{
+						Name:      "tap/bar.tap",
+						NumTests:  2,
+						NumFailed: 1,
+						Duration:  0.003,
+						TestCases: []*api.TestCase{
+							{
+								Name: "object should be a Bar",
+							},
+							{
+								Name:     "object.isBar should return true",
+								Duration: 0.003,
+								FailureOutput: &api.FailureOutput{
+									Message: "expected true",
+									Output:  "message: \"expected true\"\nfound: false\nwanted: true\nduration_ms: 3.2",
+								},
+							},
+						},
+					},
+					{
+						Name:     "tap",
+						NumTests: 1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "baz",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "bail out",
+			testFile: "3.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "tap",
+						NumTests:  3,
+						NumFailed: 2,
+						TestCases: []*api.TestCase{
+							{
+								Name: "first",
+							},
+							{
+								Name: "second",
+								FailureOutput: &api.FailureOutput{
+									Output: "# diagnostic before failure",
+								},
+							},
+							{
+								Name: "Bail out!",
+								FailureOutput: &api.FailureOutput{
+									Message: "MySQL is not running.",
+									Output:  "Bail out! MySQL is not running.",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "unfulfilled plan",
+			testFile: "4.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "tap",
+						NumTests:  3,
+						NumFailed: 1,
+						TestCases: []*api.TestCase{
+							{
+								Name: "first",
+							},
+							{
+								Name: "second",
+							},
+							{
+								Name: "plan",
+								FailureOutput: &api.FailureOutput{
+									Message: "planned 3 tests but ran 2",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := NewParser(flat.NewTestSuitesBuilder(), false)
+
+			testFile := "./../../../test/tap/testdata/" + testCase.testFile
+
+			reader, err := os.Open(testFile)
+			if err != nil {
+				t.Fatalf("unexpected error opening file %q: %v", testFile, err)
+			}
+			testSuites, err := parser.Parse(bufio.NewScanner(reader))
+			if err != nil {
+				t.Fatalf("unexpected error parsing file: %v", err)
+			}
+
+			if !reflect.DeepEqual(testSuites, testCase.expectedSuites) {
+				t.Errorf("did not produce the correct test suites from file:\n%s\n%s", testCase.expectedSuites, testSuites)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package tap
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/nested"
+)
+
+// TestNestedParse tests that parsing the TAP output in the test directory with a nested builder works as expected
+func TestNestedParse(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		testFile       string
+		rootSuiteNames []string
+		expectedSuites *api.TestSuites
+	}{
+		{
+			name:     "subtests",
+			testFile: "2.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "tap",
+						NumTests:  6,
+						NumFailed: 1,
+						Duration:  0.015,
+						TestCases: []*api.TestCase{
+							{
+								Name: "baz",
+							},
+						},
+						Children: []*api.TestSuite{
+							{
+								Name:      "tap/bar.tap",
+								NumTests:  3,
+								NumFailed: 1,
+								Duration:  0.003,
+								TestCases: []*api.TestCase{
+									{
+										Name: "object should be a Bar",
+									},
+									{
+										Name:     "object.isBar should return true",
+										Duration: 0.003,
+										FailureOutput: &api.FailureOutput{
+											Message: "expected true",
+											Output:  "message: \"expected true\"\nfound: false\nwanted: true\nduration_ms: 3.2",
+										},
+									},
+								},
+								Children: []*api.TestSuite{
+									{
+										Name:     "tap/bar.tap/nested",
+										NumTests: 1,
+										TestCases: []*api.TestCase{
+											{
+												Name: "deep",
+											},
+										},
+									},
+								},
+							},
+							{
+								Name:     "tap/foo.tap",
+								NumTests: 2,
+								Duration: 0.012,
+								TestCases: []*api.TestCase{
+									{
+										Name: "test 1",
+									},
+									{
+										Name:     "this passed",
+										Duration: 0.012,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "subtests with restricted root",
+			testFile:       "2.txt",
+			rootSuiteNames: []string{"tap/foo.tap"},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:     "tap/foo.tap",
+						NumTests: 2,
+						Duration: 0.012,
+						TestCases: []*api.TestCase{
+							{
+								Name: "test 1",
+							},
+							{
+								Name:     "this passed",
+								Duration: 0.012,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := NewParser(nested.NewTestSuitesBuilder(testCase.rootSuiteNames), false)
+
+			testFile := "./../../../test/tap/testdata/" + testCase.testFile
+
+			reader, err := os.Open(testFile)
+			if err != nil {
+				t.Fatalf("unexpected error opening file %q: %v", testFile, err)
+			}
+			testSuites, err := parser.Parse(bufio.NewScanner(reader))
+			if err != nil {
+				t.Fatalf("unexpected error parsing file: %v", err)
+			}
+
+			if !reflect.DeepEqual(testSuites, testCase.expectedSuites) {
+				t.Errorf("did not produce the correct test suites from file:\n%s\n%s", testCase.expectedSuites, testSuites)
+			}
+		})
+	}
+}
@@ -31,6 +31,18 @@ for suite in test/*/; do
 			exit 1
 		fi
 
+		if ! junitreport validate <"${WORKINGDIR}/${test_name}_flat.xml" >"${WORKINGDIR}/${test_name}_violations.txt"; then
+			cat "${WORKINGDIR}/${test_name}_violations.txt"
+			echo "[FAIL] Test '${test_name}' in suite '${suite_name}' produced invalid jUnit XML for flat suite builder."
+			exit 1
+		fi
+
+		junitreport -type "${suite_name}" -suites flat -incremental <"${test}" >"${WORKINGDIR}/${test_name}_incremental.xml"
+		if ! diff ${diff_args} "${suite}/reports/${test_name}_flat.xml" "${WORKINGDIR}/${test_name}_incremental.xml"; then
+			echo "[FAIL] Test '${test_name}' in suite '${suite_name}' failed for incremental output."
+			exit 1
+		fi
+
 		junitreport summarize <"${WORKINGDIR}/${test_name}_flat.xml" >"${WORKINGDIR}/${test_name}_summary.txt"
 		if ! diff ${diff_args} "${suite}/summaries/${test_name}_summary.txt" "${WORKINGDIR}/${test_name}_summary.txt"; then
 			echo "[FAIL] Test '${test_name}' in suite '${suite_name}' failed to summarize flat XML."